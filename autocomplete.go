@@ -0,0 +1,353 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"container/heap"
+	"errors"
+)
+
+const defaultAutocompleteResults = 10
+
+type autocompleteParams struct {
+	dictOpts     *dictOptions
+	editDistance int
+	maxResults   int
+}
+
+func (s *Spell) defaultAutocompleteParams() *autocompleteParams {
+	return &autocompleteParams{
+		dictOpts:   s.defaultDictOptions(),
+		maxResults: defaultAutocompleteResults,
+	}
+}
+
+// AutocompleteOption is a function that controls how an Autocomplete is
+// performed. An error will be returned if the AutocompleteOption is invalid.
+type AutocompleteOption func(*autocompleteParams) error
+
+// AutocompleteDictionaryOpts accepts multiple DictionaryOption and controls
+// what dictionary should be used during autocompletion.
+func AutocompleteDictionaryOpts(opts ...DictionaryOption) AutocompleteOption {
+	return func(ap *autocompleteParams) error {
+		for _, opt := range opts {
+			if err := opt(ap.dictOpts); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// AutocompleteEditDistance allows typos in the prefix to be tolerated, up to
+// the given edit distance. As with lookups, inserting, deleting or
+// substituting a rune, or swapping two adjacent runes, is a single edit. By
+// default, only exact prefixes are completed.
+func AutocompleteEditDistance(dist int) AutocompleteOption {
+	return func(ap *autocompleteParams) error {
+		if dist < 0 {
+			return errors.New("edit distance must not be negative")
+		}
+
+		ap.editDistance = dist
+
+		return nil
+	}
+}
+
+// AutocompleteMaxResults sets the maximum number of completions returned. By
+// default, 10 completions are returned.
+func AutocompleteMaxResults(n int) AutocompleteOption {
+	return func(ap *autocompleteParams) error {
+		if n < 1 {
+			return errors.New("max results must be greater than 0")
+		}
+
+		ap.maxResults = n
+
+		return nil
+	}
+}
+
+// Autocomplete returns words from the dictionary which start with prefix,
+// ordered by the distance of their prefix from the given prefix and then by
+// their frequency.
+//
+// Accepts zero or more AutocompleteOption that can be used to configure how
+// autocompletion occurs.
+func (s *Spell) Autocomplete(prefix string, opts ...AutocompleteOption) (SuggestionList, error) {
+	autocompleteParams := s.defaultAutocompleteParams()
+
+	for _, opt := range opts {
+		if err := opt(autocompleteParams); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.library.complete(autocompleteParams.dictOpts.name, []rune(prefix),
+		autocompleteParams.editDistance, autocompleteParams.maxResults, nil), nil
+}
+
+// trieNode is a node in a trie of the words in a dictionary. Each node keeps
+// track of the highest frequency of any word beneath it, so the most frequent
+// completions can be found without visiting every word.
+type trieNode struct {
+	children  map[rune]*trieNode
+	terminal  bool
+	word      string
+	frequency uint64
+	best      uint64
+}
+
+// insert adds word to the trie, or updates its frequency if it exists.
+func (t *trieNode) insert(word string, frequency uint64) {
+	path := []*trieNode{t}
+	node := t
+
+	for _, r := range word {
+		child, exists := node.children[r]
+		if !exists {
+			if node.children == nil {
+				node.children = make(map[rune]*trieNode)
+			}
+
+			child = new(trieNode)
+			node.children[r] = child
+		}
+
+		node = child
+		path = append(path, node)
+	}
+
+	node.terminal = true
+	node.word = word
+	node.frequency = frequency
+
+	updateBest(path)
+}
+
+// remove deletes word from the trie, pruning any nodes left without words.
+func (t *trieNode) remove(word string) {
+	runes := []rune(word)
+	path := []*trieNode{t}
+	node := t
+
+	for _, r := range runes {
+		child, exists := node.children[r]
+		if !exists {
+			return
+		}
+
+		node = child
+		path = append(path, node)
+	}
+
+	node.terminal = false
+	node.word = ""
+	node.frequency = 0
+
+	for i := len(path) - 1; i > 0; i-- {
+		if path[i].terminal || len(path[i].children) > 0 {
+			break
+		}
+
+		delete(path[i-1].children, runes[i-1])
+	}
+
+	updateBest(path)
+}
+
+// updateBest recalculates the highest frequency beneath each node of path,
+// working back from its end.
+func updateBest(path []*trieNode) {
+	for i := len(path) - 1; i >= 0; i-- {
+		node := path[i]
+		node.best = 0
+
+		if node.terminal {
+			node.best = node.frequency
+		}
+
+		for _, child := range node.children {
+			if child.best > node.best {
+				node.best = child.best
+			}
+		}
+	}
+}
+
+// prefixMatch is a node whose path from the root is within an edit distance
+// of a prefix.
+type prefixMatch struct {
+	node     *trieNode
+	distance int
+}
+
+// match returns the nodes whose path from the root is within maxDist of
+// prefix. Nodes beneath a match at the same or a lower distance are omitted.
+func (t *trieNode) match(prefix []rune, maxDist int) []prefixMatch {
+	if maxDist == 0 {
+		node := t
+		for _, r := range prefix {
+			if node = node.children[r]; node == nil {
+				return nil
+			}
+		}
+
+		return []prefixMatch{{node, 0}}
+	}
+
+	// Compute the optimal string alignment distance of the prefix to each path
+	// in the trie, one row of the matrix per rune of the path. Like the
+	// Damerau-Levenshtein distance used by lookups, this counts swapping two
+	// adjacent runes as a single edit
+	row := make([]int, len(prefix)+1)
+	for i := range row {
+		row[i] = i
+	}
+
+	var (
+		matches []prefixMatch
+		walk    func(node *trieNode, r, prevRune rune, prevPrev, prev []int, matched int)
+	)
+
+	walk = func(node *trieNode, r, prevRune rune, prevPrev, prev []int, matched int) {
+		cur := make([]int, len(prev))
+		cur[0] = prev[0] + 1
+		rowMin := cur[0]
+
+		for i := 1; i < len(cur); i++ {
+			cost := 1
+			if prefix[i-1] == r {
+				cost = 0
+			}
+
+			cur[i] = min(min(cur[i-1]+1, prev[i]+1), prev[i-1]+cost)
+
+			if prevPrev != nil && i > 1 && prefix[i-1] != prefix[i-2] &&
+				prefix[i-1] == prevRune && prefix[i-2] == r {
+				cur[i] = min(cur[i], prevPrev[i-2]+1)
+			}
+
+			rowMin = min(rowMin, cur[i])
+		}
+
+		if dist := cur[len(cur)-1]; dist <= maxDist && dist < matched {
+			matches = append(matches, prefixMatch{node, dist})
+			matched = dist
+		}
+
+		if rowMin > maxDist {
+			return
+		}
+
+		for childRune, child := range node.children {
+			walk(child, childRune, r, prev, cur, matched)
+		}
+	}
+
+	matched := maxDist + 1
+	if row[len(row)-1] <= maxDist {
+		matches = append(matches, prefixMatch{t, row[len(row)-1]})
+		matched = row[len(row)-1]
+	}
+
+	for r, child := range t.children {
+		walk(child, r, 0, nil, row, matched)
+	}
+
+	return matches
+}
+
+// completion is an item in the queue of nodes and words to be visited when
+// finding completions.
+type completion struct {
+	node      *trieNode
+	word      bool
+	distance  int
+	frequency uint64
+}
+
+type completionQueue []completion
+
+func (q completionQueue) Len() int { return len(q) }
+
+func (q completionQueue) Less(i, j int) bool {
+	if q[i].distance != q[j].distance {
+		return q[i].distance < q[j].distance
+	}
+
+	return q[i].frequency > q[j].frequency
+}
+
+func (q completionQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *completionQueue) Push(x interface{}) { *q = append(*q, x.(completion)) }
+
+func (q *completionQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	c := old[n-1]
+	*q = old[:n-1]
+
+	return c
+}
+
+// complete returns up to n words from a given dictionary which start with a
+// prefix within maxDist of prefix, ordered by distance and then frequency. If
+// accept is not nil, only words whose entry it accepts are returned.
+func (l *library) complete(dict string, prefix []rune, maxDist, n int, accept func(Entry) bool) SuggestionList {
+	results := SuggestionList{}
+
+	d := l.dictionary(dict, false)
+	if d == nil {
+		return results
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	root := d.trie
+
+	// Visit nodes in order of the best completion beneath them, so the first
+	// n words reached are the best n completions
+	queue := completionQueue{}
+	for _, m := range root.match(prefix, maxDist) {
+		queue = append(queue, completion{node: m.node, distance: m.distance, frequency: m.node.best})
+	}
+
+	heap.Init(&queue)
+
+	seen := make(map[string]struct{})
+
+	for queue.Len() > 0 && len(results) < n {
+		c := heap.Pop(&queue).(completion)
+
+		if c.word {
+			entry, _ := d.load(c.node.word)
+
+			if addKey(seen, c.node.word) && (accept == nil || accept(entry)) {
+				results = append(results, Suggestion{
+					Distance:   c.distance,
+					Dictionary: dict,
+					Entry:      entry,
+				})
+			}
+
+			continue
+		}
+
+		if c.node.terminal {
+			heap.Push(&queue, completion{node: c.node, word: true, distance: c.distance, frequency: c.node.frequency})
+		}
+
+		for _, child := range c.node.children {
+			heap.Push(&queue, completion{node: child, distance: c.distance, frequency: child.best})
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,96 @@
+package spell_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func ExampleSpell_Autocomplete() {
+	// Create a new speller
+	s := spell.New()
+
+	_, _ = s.AddEntry(spell.Entry{Frequency: 10, Word: "spell"})
+	_, _ = s.AddEntry(spell.Entry{Frequency: 50, Word: "spelling"})
+	_, _ = s.AddEntry(spell.Entry{Frequency: 5, Word: "speller"})
+	_, _ = s.AddEntry(spell.Entry{Frequency: 100, Word: "special"})
+
+	// Complete a prefix, returning the most frequent words first
+	suggestions, _ := s.Autocomplete("spel")
+	fmt.Println(suggestions)
+	// Output:
+	// [spelling, spell, speller]
+}
+
+func TestAutocomplete(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"spell": 10, "spelling": 50, "speller": 5, "special": 100, "spa": 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s.Autocomplete("spel", spell.AutocompleteMaxResults(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[spelling, spell]" {
+		t.Fatalf("expected [spelling, spell], got %s", got)
+	}
+
+	suggestions, err = s.Autocomplete("sepl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 0 {
+		t.Fatalf("expected no completions, got %s", suggestions)
+	}
+
+	// Tolerate a typo in the prefix
+	suggestions, err = s.Autocomplete("spwl", spell.AutocompleteEditDistance(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[spelling, spell, speller]" {
+		t.Fatalf("expected [spelling, spell, speller], got %s", got)
+	}
+	if suggestions[0].Distance != 1 {
+		t.Fatalf("expected distance 1, got %d", suggestions[0].Distance)
+	}
+
+	// Swapping adjacent runes of the prefix is a single edit
+	suggestions, err = s.Autocomplete("sepl", spell.AutocompleteEditDistance(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[spelling, spell, speller]" || suggestions[0].Distance != 1 {
+		t.Fatalf("expected [spelling, spell, speller] at distance 1, got %s", got)
+	}
+
+	// Removed words are no longer completed
+	if _, err := s.RemoveEntry("spelling"); err != nil {
+		t.Fatal(err)
+	}
+	suggestions, err = s.Autocomplete("spel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[spell, speller]" {
+		t.Fatalf("expected [spell, speller], got %s", got)
+	}
+
+	// Completions come from the selected dictionary
+	if _, err := s.AddEntry(spell.Entry{Word: "spécial"},
+		spell.DictionaryName("french")); err != nil {
+		t.Fatal(err)
+	}
+	suggestions, err = s.Autocomplete("spé",
+		spell.AutocompleteDictionaryOpts(spell.DictionaryName("french")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[spécial]" {
+		t.Fatalf("expected [spécial], got %s", got)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Misspelling is a word within some text which isn't in the dictionary, or
+// any word checked when using CheckKnownWords.
+type Misspelling struct {
+	// The byte offsets of the word within the text
+	Start, End int
+
+	// The rune offsets of the word within the text
+	RuneStart, RuneEnd int
+
+	Word        string
+	Suggestions SuggestionList
+
+	// Whether the word is known, which is only reported by CheckKnownWords
+	Known bool
+}
+
+type checkParams struct {
+	ignoreRules   []IgnoreRule
+	lookup        func(context.Context, string, ...LookupOption) (SuggestionList, error)
+	lookupOptions []LookupOption
+	overlay       *Overlay
+	knownWords    bool
+}
+
+func (s *Spell) defaultCheckParams() *checkParams {
+	return &checkParams{
+		ignoreRules: DefaultIgnoreRules(),
+		lookup:      s.LookupContext,
+		lookupOptions: []LookupOption{
+			SuggestionLevel(LevelClosest),
+		},
+	}
+}
+
+// CheckOption is a function that controls how text is checked. An error will
+// be returned if the CheckOption is invalid.
+type CheckOption func(*checkParams) error
+
+// CheckLookupOpts allows the Lookup() options used for each word to be
+// configured, such as the suggestion level and the dictionaries used. By
+// default, the closest suggestions from the default dictionary are returned.
+func CheckLookupOpts(opts ...LookupOption) CheckOption {
+	return func(cp *checkParams) error {
+		cp.lookupOptions = opts
+
+		return nil
+	}
+}
+
+// CheckIgnoreRules sets the rules which find the parts of the text which
+// aren't checked, replacing the default rule pack returned by
+// DefaultIgnoreRules. Any word overlapping a part found by a rule is ignored.
+// If no rules are given, every word is checked.
+func CheckIgnoreRules(rules ...IgnoreRule) CheckOption {
+	return func(cp *checkParams) error {
+		for _, rule := range rules {
+			if rule == nil {
+				return errors.New("ignore rule must not be nil")
+			}
+		}
+
+		cp.ignoreRules = rules
+
+		return nil
+	}
+}
+
+// CheckOverlay checks words using the overlay, so that its words are known and
+// its ignored words aren't reported. The overlay should wrap the Spell the
+// check is performed with.
+func CheckOverlay(o *Overlay) CheckOption {
+	return func(cp *checkParams) error {
+		if o == nil {
+			return errors.New("overlay must not be nil")
+		}
+
+		cp.lookup = o.LookupContext
+		cp.overlay = o
+
+		return nil
+	}
+}
+
+// CheckKnownWords reports every word checked rather than only those which
+// aren't known, with Known set for each word which is, such as to show the
+// words which were checked.
+func CheckKnownWords() CheckOption {
+	return func(cp *checkParams) error {
+		cp.knownWords = true
+
+		return nil
+	}
+}
+
+// CheckText splits text into words using Unicode word boundaries, and returns
+// each word which isn't in the dictionary along with suggestions for it. A
+// word is also known if its lower case form is in the dictionary. Parts of the
+// text which aren't words, such as URLs and numbers, are ignored as described
+// by CheckIgnoreRules.
+//
+// Accepts zero or more CheckOption that can be used to configure how words are
+// checked.
+func (s *Spell) CheckText(text string, opts ...CheckOption) ([]Misspelling, error) {
+	return s.CheckTextContext(context.Background(), text, opts...)
+}
+
+// CheckTextContext is like CheckText but stops once ctx is done. In that case
+// the misspellings found so far are returned, along with an error wrapping
+// both ErrInterrupted and the context's error.
+func (s *Spell) CheckTextContext(ctx context.Context, text string, opts ...CheckOption) ([]Misspelling, error) {
+	return s.check(ctx, text, tokenize, nil, opts)
+}
+
+// check checks each of the words of text found by split, other than those
+// which are ignored. If locate is not nil, it's used to map each word's offsets
+// from the text to those of the source the text was taken from.
+func (s *Spell) check(ctx context.Context, text string, split func(string) []token,
+	locate func(token) token, opts []CheckOption) ([]Misspelling, error) {
+	checkParams := s.defaultCheckParams()
+
+	for _, opt := range opts {
+		if err := opt(checkParams); err != nil {
+			return nil, err
+		}
+	}
+
+	var misspellings []Misspelling
+
+	tokens := removeIgnored(split(text), ignoredSpans(text, checkParams.ignoreRules))
+
+	for _, t := range tokens {
+		suggestions, known, err := s.checkWord(ctx, t.word, checkParams)
+		if err != nil {
+			return misspellings, err
+		}
+
+		if known && !checkParams.knownWords {
+			continue
+		}
+
+		if locate != nil {
+			t = locate(t)
+		}
+
+		if !known && checkParams.overlay != nil && checkParams.overlay.ignoredOnce(t.word, t.start) {
+			if !checkParams.knownWords {
+				continue
+			}
+
+			known = true
+		}
+
+		misspellings = append(misspellings, Misspelling{
+			Start:       t.start,
+			End:         t.end,
+			RuneStart:   t.runeStart,
+			RuneEnd:     t.runeEnd,
+			Word:        t.word,
+			Suggestions: suggestions,
+			Known:       known,
+		})
+	}
+
+	return misspellings, nil
+}
+
+// checkWord looks up word, returning its suggestions and whether it, or its
+// lower case form, is in the dictionary.
+func (s *Spell) checkWord(ctx context.Context, word string, cp *checkParams) (SuggestionList, bool, error) {
+	suggestions, err := cp.lookup(ctx, word, cp.lookupOptions...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if suggestions.exact() {
+		return suggestions, true, nil
+	}
+
+	if lower := strings.ToLower(word); lower != word {
+		lowerSuggestions, err := cp.lookup(ctx, lower, cp.lookupOptions...)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if lowerSuggestions.exact() {
+			return lowerSuggestions, true, nil
+		}
+
+		if len(suggestions) == 0 {
+			suggestions = lowerSuggestions
+		}
+	}
+
+	return suggestions, false, nil
+}
+
+// exact reports whether the list contains a suggestion with a distance of 0.
+func (s SuggestionList) exact() bool {
+	for _, sugg := range s {
+		if sugg.Distance == 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package spell_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func TestCheckText(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"the": 1000, "quick": 100, "brown": 100, "fox": 100, "don't": 10,
+		"jumps": 50, "e.g": 5, "café": 5, "over": 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "The quikc brown fox, e.g. don't jumpz ovr 3 café's 42.5 cafe"
+
+	misspellings, err := s.CheckText(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type misspelling struct {
+		word       string
+		suggestion string
+	}
+
+	expected := []misspelling{
+		{"quikc", "quick"},
+		{"jumpz", "jumps"},
+		{"ovr", "over"},
+		{"café's", "café"},
+		{"cafe", "café"},
+	}
+
+	if len(misspellings) != len(expected) {
+		t.Fatalf("expected %d misspellings, got %+v", len(expected), misspellings)
+	}
+
+	runes := []rune(text)
+
+	for i, m := range misspellings {
+		if m.Word != expected[i].word {
+			t.Errorf("expected %q, got %q", expected[i].word, m.Word)
+		}
+		if text[m.Start:m.End] != m.Word {
+			t.Errorf("byte offsets of %q give %q", m.Word, text[m.Start:m.End])
+		}
+		if string(runes[m.RuneStart:m.RuneEnd]) != m.Word {
+			t.Errorf("rune offsets of %q give %q", m.Word, string(runes[m.RuneStart:m.RuneEnd]))
+		}
+		if len(m.Suggestions) == 0 || m.Suggestions[0].Word != expected[i].suggestion {
+			t.Errorf("expected suggestion %q for %q, got %v", expected[i].suggestion, m.Word, m.Suggestions)
+		}
+	}
+}
+
+func TestCheckText_lookupOpts(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"the": 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.AddEntry(spell.Entry{Word: "colour", Frequency: 10},
+		spell.DictionaryName("british")); err != nil {
+		t.Fatal(err)
+	}
+
+	misspellings, err := s.CheckText("the colour", spell.CheckLookupOpts(
+		spell.DictionaryOpts(spell.DictionaryName("british"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(misspellings) != 1 || misspellings[0].Word != "the" {
+		t.Fatalf("expected only \"the\" to be misspelt, got %+v", misspellings)
+	}
+
+	misspellings, err = s.CheckText("thw colour", spell.CheckLookupOpts(
+		spell.Dictionaries("default", "british"),
+		spell.SuggestionLevel(spell.LevelAll)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(misspellings) != 1 || misspellings[0].Word != "thw" ||
+		len(misspellings[0].Suggestions) != 1 {
+		t.Fatalf("expected \"thw\" to be misspelt, got %+v", misspellings)
+	}
+}
+
+func TestCheckText_knownWords(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"the": 1000, "fox": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := spell.NewOverlay(s)
+	o.IgnoreOnce("brwn", 4)
+
+	words, err := s.CheckText("The brwn fox jumpd 42", spell.CheckKnownWords(), spell.CheckOverlay(o))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		word  string
+		known bool
+	}{{"The", true}, {"brwn", true}, {"fox", true}, {"jumpd", false}}
+
+	if len(words) != len(expected) {
+		t.Fatalf("expected %d words, got %+v", len(expected), words)
+	}
+
+	for i, w := range words {
+		if w.Word != expected[i].word || w.Known != expected[i].known {
+			t.Errorf("expected %q with known %v, got %q with known %v",
+				expected[i].word, expected[i].known, w.Word, w.Known)
+		}
+	}
+}
+
+func TestCheckTextContext(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"the": 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.CheckTextContext(ctx, "thw"); !errors.Is(err, spell.ErrInterrupted) {
+		t.Fatalf("expected ErrInterrupted, got %v", err)
+	}
+}
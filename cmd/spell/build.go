@@ -0,0 +1,96 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/eskriett/spell"
+)
+
+// runBuild creates a dictionary from word lists or frequency files.
+func runBuild(env *env, args []string) error {
+	fs := newFlagSet(env, "build", "[file ...]")
+	out := fs.String("o", "", "the .spell `file` to write (required)")
+	name := fs.String("dictionary", "", "the `name` of the dictionary to add words to, rather than the default")
+	editDistance := fs.Uint("edit-distance", 2, "the maximum edit `distance` of lookups")
+	prefixLength := fs.Uint("prefix-length", 7, "the `length` of the prefix of words which is indexed")
+	ngrams := fs.String("ngrams", "", "a `file` of n-gram counts to add")
+	asJSON := fs.Bool("json", false, "print the dictionary's statistics as JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return errors.New("an output file must be given with -o")
+	}
+
+	s := spell.New()
+	s.MaxEditDistance = uint32(*editDistance)
+	s.PrefixLength = uint32(*prefixLength)
+
+	var opts []spell.DictionaryOption
+	if *name != "" {
+		opts = append(opts, spell.DictionaryName(*name))
+	}
+
+	// Words are read from stdin when no files are given
+	err := eachInput(env, fs.Args(), func(filename string, r io.Reader) error {
+		if err := s.ReadEntries(r, opts...); err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if *ngrams != "" {
+		f, err := os.Open(*ngrams)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if err := s.ReadNGrams(f, opts...); err != nil {
+			return fmt.Errorf("%s: %w", *ngrams, err)
+		}
+	}
+
+	if err := s.Save(*out); err != nil {
+		return err
+	}
+
+	return printStats(env.stdout, s.Stats(), *asJSON)
+}
+
+// eachInput calls f with each of the named files, or with stdin if there are
+// none.
+func eachInput(env *env, filenames []string, f func(filename string, r io.Reader) error) error {
+	if len(filenames) == 0 {
+		return f("<stdin>", env.stdin)
+	}
+
+	for _, filename := range filenames {
+		file, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+
+		err = f(filename, file)
+		file.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eskriett/spell"
+	"github.com/eskriett/spell/internal/lines"
+)
+
+// checkResult is the JSON output for a misspelling.
+type checkResult struct {
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	Column      int      `json:"column"`
+	Offset      int      `json:"offset"`
+	Word        string   `json:"word"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// runCheck reports misspellings in files, or in stdin.
+func runCheck(env *env, args []string) error {
+	fs := newFlagSet(env, "check", "[file ...]")
+
+	var df dictionaryFlags
+	df.register(fs)
+
+	format := fs.String("format", "auto",
+		"the format of the input: text, html, markdown or go, or auto to choose by file extension")
+	maxResults := fs.Int("max", 5, "the maximum `number` of suggestions for each misspelling, or 0 for no limit")
+	asJSON := fs.Bool("json", false, "print JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := lookupOptions(&df, "closest", -1, *maxResults)
+	if err != nil {
+		return err
+	}
+
+	s, err := df.load()
+	if err != nil {
+		return err
+	}
+
+	results := []checkResult{}
+
+	err = eachInput(env, fs.Args(), func(filename string, r io.Reader) error {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		misspellings, err := check(s, filename, *format, data, spell.CheckLookupOpts(opts...))
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+
+		index := lines.New(string(data))
+
+		for _, m := range misspellings {
+			// Lines and columns are 1-based, and columns count runes
+			line, prefix := index.Position(m.Start)
+
+			results = append(results, checkResult{
+				File:        filename,
+				Line:        line + 1,
+				Column:      utf8.RuneCountInString(prefix) + 1,
+				Offset:      m.Start,
+				Word:        m.Word,
+				Suggestions: m.Suggestions.GetWords(),
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		err = writeJSON(env.stdout, results)
+	} else {
+		for _, result := range results {
+			fmt.Fprintf(env.stdout, "%s:%d:%d: %s", result.File, result.Line, result.Column, result.Word)
+
+			if len(result.Suggestions) > 0 {
+				fmt.Fprintf(env.stdout, " (%s)", strings.Join(result.Suggestions, ", "))
+			}
+
+			fmt.Fprintln(env.stdout)
+		}
+	}
+
+	if err == nil && len(results) > 0 {
+		err = errFound
+	}
+
+	return err
+}
+
+// check checks data in the given format, choosing it by the extension of
+// filename if it's auto.
+func check(s *spell.Spell, filename, format string, data []byte, opts ...spell.CheckOption) ([]spell.Misspelling, error) {
+	if format == "auto" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".html", ".htm":
+			format = "html"
+		case ".md", ".markdown":
+			format = "markdown"
+		case ".go":
+			format = "go"
+		default:
+			format = "text"
+		}
+	}
+
+	switch format {
+	case "text":
+		return s.CheckText(string(data), opts...)
+	case "html":
+		return s.CheckHTML(string(data), opts...)
+	case "markdown":
+		return s.CheckMarkdown(string(data), opts...)
+	case "go":
+		sourceMisspellings, err := s.CheckGoSource(filename, data, opts...)
+
+		misspellings := make([]spell.Misspelling, 0, len(sourceMisspellings))
+		for _, m := range sourceMisspellings {
+			misspellings = append(misspellings, m.Misspelling)
+		}
+
+		return misspellings, err
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
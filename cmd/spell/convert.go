@@ -0,0 +1,150 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/eskriett/spell"
+)
+
+// The formats a dictionary can be converted between.
+const (
+	// formatSpell is the gzipped JSON written by Spell.Save
+	formatSpell = "spell"
+
+	// formatJSON is the uncompressed JSON written by Spell.Write
+	formatJSON = "json"
+
+	// formatFreq is the list of words and their frequencies written by
+	// Spell.WriteEntries, which holds a single dictionary
+	formatFreq = "freq"
+)
+
+// runConvert converts a dictionary between formats.
+func runConvert(env *env, args []string) error {
+	fs := newFlagSet(env, "convert", "input output")
+	from := fs.String("from", "", "the `format` of the input: spell, json or freq (default: by file extension)")
+	to := fs.String("to", "", "the `format` of the output: spell, json or freq (default: by file extension)")
+	name := fs.String("dictionary", "", "the `name` of the dictionary read from or written to freq files, "+
+		"rather than the default")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+
+		return errors.New("an input and an output must be given, either of which may be - for stdin or stdout")
+	}
+
+	input, output := fs.Arg(0), fs.Arg(1)
+
+	var opts []spell.DictionaryOption
+	if *name != "" {
+		opts = append(opts, spell.DictionaryName(*name))
+	}
+
+	r := env.stdin
+
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		r = f
+	}
+
+	s, err := readDictionary(r, formatOf(*from, input), opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", input, err)
+	}
+
+	if output == "-" {
+		return writeDictionary(env.stdout, s, formatOf(*to, output), opts)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	if err := writeDictionary(f, s, formatOf(*to, output), opts); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// formatOf returns format, or if it's empty, the format given by the extension
+// of filename.
+func formatOf(format, filename string) string {
+	if format != "" {
+		return format
+	}
+
+	switch filepath.Ext(filename) {
+	case ".spell":
+		return formatSpell
+	case ".json":
+		return formatJSON
+	}
+
+	return formatFreq
+}
+
+// readDictionary reads a dictionary in the given format from r.
+func readDictionary(r io.Reader, format string, opts []spell.DictionaryOption) (*spell.Spell, error) {
+	switch format {
+	case formatSpell:
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+
+		return spell.Read(gz)
+	case formatJSON:
+		return spell.Read(r)
+	case formatFreq:
+		s := spell.New()
+		if err := s.ReadEntries(r, opts...); err != nil {
+			return nil, err
+		}
+
+		return s, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// writeDictionary writes s to w in the given format.
+func writeDictionary(w io.Writer, s *spell.Spell, format string, opts []spell.DictionaryOption) error {
+	switch format {
+	case formatSpell:
+		gz := gzip.NewWriter(w)
+		if err := s.Write(gz); err != nil {
+			return err
+		}
+
+		return gz.Close()
+	case formatJSON:
+		return s.Write(w)
+	case formatFreq:
+		return s.WriteEntries(w, opts...)
+	}
+
+	return fmt.Errorf("unknown format %q", format)
+}
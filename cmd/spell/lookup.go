@@ -0,0 +1,123 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/eskriett/spell"
+)
+
+// lookupResult is the JSON output for a word looked up.
+type lookupResult struct {
+	Input       string               `json:"input"`
+	Suggestions spell.SuggestionList `json:"suggestions"`
+}
+
+// runLookup suggests corrections for words.
+func runLookup(env *env, args []string) error {
+	fs := newFlagSet(env, "lookup", "[word ...]")
+
+	var df dictionaryFlags
+	df.register(fs)
+
+	level := fs.String("level", "best", "the suggestions to return: best, closest or all")
+	distance := fs.Int("distance", -1, "the maximum edit `distance` of suggestions, rather than the dictionary's")
+	maxResults := fs.Int("max", 0, "the maximum `number` of suggestions, or 0 for no limit")
+	asJSON := fs.Bool("json", false, "print JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	opts, err := lookupOptions(&df, *level, *distance, *maxResults)
+	if err != nil {
+		return err
+	}
+
+	s, err := df.load()
+	if err != nil {
+		return err
+	}
+
+	// Words are read from stdin when none are given
+	words := fs.Args()
+	if len(words) == 0 {
+		scanner := bufio.NewScanner(env.stdin)
+		for scanner.Scan() {
+			words = append(words, strings.Fields(scanner.Text())...)
+		}
+
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	// JSON output includes the score and probability of each suggestion
+	if *asJSON {
+		opts = append(opts, spell.Probabilities())
+	}
+
+	results := make([]lookupResult, 0, len(words))
+
+	for _, word := range words {
+		suggestions, err := s.Lookup(word, opts...)
+		if err != nil {
+			return err
+		}
+
+		results = append(results, lookupResult{Input: word, Suggestions: suggestions})
+	}
+
+	if *asJSON {
+		return writeJSON(env.stdout, results)
+	}
+
+	for _, result := range results {
+		if len(result.Suggestions) == 0 {
+			fmt.Fprintf(env.stdout, "%s: no suggestions\n", result.Input)
+
+			continue
+		}
+
+		descriptions := make([]string, 0, len(result.Suggestions))
+		for _, sugg := range result.Suggestions {
+			descriptions = append(descriptions, fmt.Sprintf("%s (distance %d, frequency %d)",
+				sugg.Word, sugg.Distance, sugg.Frequency))
+		}
+
+		fmt.Fprintf(env.stdout, "%s: %s\n", result.Input, strings.Join(descriptions, ", "))
+	}
+
+	return nil
+}
+
+// lookupOptions returns the options for looking up words given by flags.
+func lookupOptions(df *dictionaryFlags, level string, distance, maxResults int) ([]spell.LookupOption, error) {
+	opts := []spell.LookupOption{spell.DictionaryOpts(df.dictionaryOpts()...)}
+
+	switch level {
+	case "best":
+		opts = append(opts, spell.SuggestionLevel(spell.LevelBest))
+	case "closest":
+		opts = append(opts, spell.SuggestionLevel(spell.LevelClosest))
+	case "all":
+		opts = append(opts, spell.SuggestionLevel(spell.LevelAll))
+	default:
+		return nil, fmt.Errorf("unknown suggestion level %q", level)
+	}
+
+	if distance >= 0 {
+		opts = append(opts, spell.EditDistance(uint32(distance)))
+	}
+
+	if maxResults > 0 {
+		opts = append(opts, spell.MaxResults(maxResults))
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"github.com/eskriett/spell/lsp"
+)
+
+// runLSP runs a language server over stdin and stdout.
+func runLSP(env *env, args []string) error {
+	fs := newFlagSet(env, "lsp", "")
+
+	var df dictionaryFlags
+	df.register(fs)
+
+	distance := fs.Int("distance", -1, "the maximum edit `distance` of suggestions, rather than the dictionary's")
+	maxResults := fs.Int("max", 5, "the maximum `number` of suggestions offered for each misspelling")
+	personal := fs.String("personal", "",
+		"the `file` holding the words added and ignored by the user, which is created if it doesn't exist")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	lookupOpts, err := lookupOptions(&df, "closest", *distance, 0)
+	if err != nil {
+		return err
+	}
+
+	opts := []lsp.Option{lsp.LookupOpts(lookupOpts...), lsp.MaxSuggestions(*maxResults)}
+	if *personal != "" {
+		opts = append(opts, lsp.PersonalDictionary(*personal))
+	}
+
+	s, err := df.load()
+	if err != nil {
+		return err
+	}
+
+	srv, err := lsp.New(s, opts...)
+	if err != nil {
+		return err
+	}
+
+	return srv.Serve(env.stdin, env.stdout)
+}
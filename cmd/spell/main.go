@@ -0,0 +1,173 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+// Command spell builds, inspects and uses spell dictionaries from the command
+// line.
+//
+// Usage:
+//
+//	spell <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	build    create a dictionary from word lists or frequency files
+//	lookup   suggest corrections for words
+//	segment  divide text into words
+//	check    report misspellings in text, HTML, Markdown or Go files
+//	stats    describe a dictionary
+//	convert  convert a dictionary between formats
+//	lsp      run a language server reporting misspellings to an editor
+//	pipe     check lines from stdin using the "ispell -a" pipe protocol
+//
+// As "spell -a" runs the pipe command, the tool may be used in place of ispell
+// or aspell by programs which run them in pipe mode.
+//
+// Dictionaries are read from and written to .spell files, as created by
+// Spell.Save. Most commands accept -json to print JSON rather than
+// human-readable output. Run "spell <command> -h" for the flags of a command.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/eskriett/spell"
+)
+
+// command is a subcommand of the tool.
+type command struct {
+	name    string
+	summary string
+	run     func(env *env, args []string) error
+}
+
+var commands = []command{
+	{"build", "create a dictionary from word lists or frequency files", runBuild},
+	{"lookup", "suggest corrections for words", runLookup},
+	{"segment", "divide text into words", runSegment},
+	{"check", "report misspellings in text, HTML, Markdown or Go files", runCheck},
+	{"stats", "describe a dictionary", runStats},
+	{"convert", "convert a dictionary between formats", runConvert},
+	{"lsp", "run a language server reporting misspellings to an editor", runLSP},
+	{"pipe", `check lines from stdin using the "ispell -a" pipe protocol`, runPipe},
+}
+
+// errFound is returned by a command which completed, but found problems to
+// report, such as misspellings.
+var errFound = errors.New("problems found")
+
+// env holds the standard streams used by a command.
+type env struct {
+	stdin          io.Reader
+	stdout, stderr io.Writer
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], &env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+// run runs the command given by args, returning the exit status: 0 on success,
+// 1 if problems were found and 2 on error.
+func run(args []string, env *env) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(env.stderr)
+
+		return 2
+	}
+
+	// Programs expecting ispell or aspell run them with -a for pipe mode
+	if args[0] == "-a" {
+		args = append([]string{"pipe"}, args[1:]...)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		err := cmd.run(env, args[1:])
+
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, errFound):
+			return 1
+		case errors.Is(err, flag.ErrHelp):
+			return 2
+		}
+
+		fmt.Fprintf(env.stderr, "spell %s: %v\n", cmd.name, err)
+
+		return 2
+	}
+
+	fmt.Fprintf(env.stderr, "spell: unknown command %q\n", args[0])
+	usage(env.stderr)
+
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: spell <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+// newFlagSet creates the flag set for a command, printing usage for the
+// command's arguments to env.
+func newFlagSet(env *env, name, arguments string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(env.stderr, "Usage: spell %s [flags] %s\n\nFlags:\n", name, arguments)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// dictionaryFlags are the flags which select a dictionary to use.
+type dictionaryFlags struct {
+	file string
+	name string
+}
+
+func (df *dictionaryFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&df.file, "d", "", "the .spell `file` to load (required)")
+	fs.StringVar(&df.name, "dictionary", "", "the `name` of the dictionary to use, rather than the default")
+}
+
+// load loads the dictionary file.
+func (df *dictionaryFlags) load() (*spell.Spell, error) {
+	if df.file == "" {
+		return nil, errors.New("a dictionary file must be given with -d")
+	}
+
+	return spell.Load(df.file)
+}
+
+// dictionaryOpts returns the options selecting the dictionary.
+func (df *dictionaryFlags) dictionaryOpts() []spell.DictionaryOption {
+	if df.name == "" {
+		return nil
+	}
+
+	return []spell.DictionaryOption{spell.DictionaryName(df.name)}
+}
+
+// writeJSON writes v to w as indented JSON.
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runTest runs the tool with args and stdin, returning its exit status and
+// output.
+func runTest(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+
+	status := run(args, &env{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr})
+
+	return status, stdout.String(), stderr.String()
+}
+
+// buildTest builds a dictionary for tests, returning its filename.
+func buildTest(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	words := filepath.Join(dir, "words.txt")
+
+	if err := os.WriteFile(words, []byte("the 1000\nquick 100\nbrown 100\nfox 100\ntwo 100\ntown 1\ntown\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(dir, "test.spell")
+
+	status, _, stderr := runTest(t, "", "build", "-o", filename, words)
+	if status != 0 {
+		t.Fatalf("build failed with status %d: %s", status, stderr)
+	}
+
+	return filename
+}
+
+func TestBuildStats(t *testing.T) {
+	filename := buildTest(t)
+
+	status, stdout, stderr := runTest(t, "", "stats", "-d", filename, "-json")
+	if status != 0 {
+		t.Fatalf("stats failed with status %d: %s", status, stderr)
+	}
+
+	var stats struct {
+		Dictionaries map[string]struct {
+			Words     uint64
+			Frequency uint64
+		}
+	}
+
+	if err := json.Unmarshal([]byte(stdout), &stats); err != nil {
+		t.Fatal(err)
+	}
+
+	if dict := stats.Dictionaries["default"]; dict.Words != 6 || dict.Frequency != 1402 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	filename := buildTest(t)
+
+	status, stdout, _ := runTest(t, "", "lookup", "-d", filename, "twon", "xyzzyx")
+	if status != 0 {
+		t.Fatalf("lookup failed with status %d", status)
+	}
+
+	expected := "twon: two (distance 1, frequency 100)\nxyzzyx: no suggestions\n"
+	if stdout != expected {
+		t.Fatalf("expected %q, got %q", expected, stdout)
+	}
+
+	status, stdout, _ = runTest(t, "twon\n", "lookup", "-d", filename, "-level", "all", "-json")
+	if status != 0 {
+		t.Fatalf("lookup failed with status %d", status)
+	}
+
+	var results []struct {
+		Input       string
+		Suggestions []struct{ Word string }
+	}
+
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || len(results[0].Suggestions) != 2 || results[0].Suggestions[1].Word != "town" {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
+
+func TestSegment(t *testing.T) {
+	filename := buildTest(t)
+
+	status, stdout, _ := runTest(t, "thequickbrownfox\nthebrownfox\n", "segment", "-d", filename)
+	if status != 0 {
+		t.Fatalf("segment failed with status %d", status)
+	}
+
+	if expected := "the quick brown fox\nthe brown fox\n"; stdout != expected {
+		t.Fatalf("expected %q, got %q", expected, stdout)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	filename := buildTest(t)
+
+	status, stdout, _ := runTest(t, "the quikc\nbrown foz\n\nfoz", "check", "-d", filename)
+	if status != 1 {
+		t.Fatalf("expected status 1, got %d", status)
+	}
+
+	expected := "<stdin>:1:5: quikc (quick)\n<stdin>:2:7: foz (fox)\n<stdin>:4:1: foz (fox)\n"
+	if stdout != expected {
+		t.Fatalf("expected %q, got %q", expected, stdout)
+	}
+
+	status, stdout, _ = runTest(t, "# The *quick* `brwn` fox", "check", "-d", filename, "-format", "markdown")
+	if status != 0 {
+		t.Fatalf("expected status 0, got %d: %s", status, stdout)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	filename := buildTest(t)
+	dir := t.TempDir()
+
+	// Convert to JSON and back to a .spell file, then list its words
+	jsonFile := filepath.Join(dir, "test.json")
+	spellFile := filepath.Join(dir, "converted.spell")
+
+	for _, args := range [][]string{
+		{"convert", filename, jsonFile},
+		{"convert", jsonFile, spellFile},
+	} {
+		if status, _, stderr := runTest(t, "", args...); status != 0 {
+			t.Fatalf("%v failed with status %d: %s", args, status, stderr)
+		}
+	}
+
+	status, stdout, stderr := runTest(t, "", "convert", "-to", "freq", spellFile, "-")
+	if status != 0 {
+		t.Fatalf("convert failed with status %d: %s", status, stderr)
+	}
+
+	expected := "the 1000\nbrown 100\nfox 100\nquick 100\ntwo 100\ntown 2\n"
+	if stdout != expected {
+		t.Fatalf("expected %q, got %q", expected, stdout)
+	}
+}
+
+func TestLSP(t *testing.T) {
+	filename := buildTest(t)
+
+	var stdin strings.Builder
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":` +
+			`{"uri":"file:///a.txt","languageId":"plaintext","version":1,"text":"the qick fox"}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&stdin, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+
+	status, stdout, stderr := runTest(t, stdin.String(), "lsp", "-d", filename)
+	if status != 0 {
+		t.Fatalf("lsp failed with status %d: %s", status, stderr)
+	}
+
+	if !strings.Contains(stdout, `"message":"Unknown word \"qick\", did you mean \"quick\"?"`) {
+		t.Fatalf("expected a diagnostic, got %q", stdout)
+	}
+}
+
+func TestPipe(t *testing.T) {
+	filename := buildTest(t)
+	personal := filepath.Join(t.TempDir(), "personal.json")
+
+	stdin := "the qick fox\n!\n^*xyzzyx brwn\n*brwn\n@xyzzyx\n#\nbrwn xyzzyx teh\n%\nfox\n"
+
+	status, stdout, stderr := runTest(t, stdin, "-a", "-d", filename, "-personal", personal)
+	if status != 0 {
+		t.Fatalf("pipe failed with status %d: %s", status, stderr)
+	}
+
+	expected := pipeBanner + "\n" +
+		"*\n& qick 1 4: quick\n*\n\n" +
+		"# xyzzyx 2\n& brwn 1 9: brown\n\n" +
+		"& teh 1 12: the\n\n" +
+		"*\n\n"
+	if stdout != expected {
+		t.Fatalf("expected %q, got %q", expected, stdout)
+	}
+
+	if _, err := os.Stat(personal); err != nil {
+		t.Fatalf("expected personal dictionary to be saved: %v", err)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	if status, _, stderr := runTest(t, "", "frobnicate"); status != 2 || !strings.Contains(stderr, "unknown command") {
+		t.Fatalf("expected status 2 with an error, got %d: %s", status, stderr)
+	}
+}
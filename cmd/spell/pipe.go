@@ -0,0 +1,198 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/eskriett/spell"
+)
+
+// pipeBanner is printed when the pipe protocol starts. Clients check the
+// version it gives to find the features of the protocol they can use.
+const pipeBanner = "@(#) International Ispell Version 3.1.20 (but really spell)"
+
+// runPipe checks lines read from stdin using the "ispell -a" pipe protocol,
+// which is also spoken by aspell, so that the tool can replace them in the
+// editors and mail clients which use it.
+//
+// Each line read is checked, and a line is written for each word: "*" if the
+// word is known, "& word count offset: sugg, sugg, ..." if it isn't, or
+// "# word offset" if there are no suggestions for it, followed by an empty
+// line. Offsets count the characters before the word within the line read.
+// Lines beginning with one of these characters are instead commands:
+//
+//	*word  add word to the personal dictionary
+//	&word  add the lower case form of word to the personal dictionary
+//	@word  accept word for the rest of the session
+//	#      save the personal dictionary
+//	!      enter terse mode, where known words aren't written
+//	%      leave terse mode
+//	^text  check text, even if it begins with a command character
+//	+ - ~ $  accepted for compatibility, but ignored
+func runPipe(env *env, args []string) error {
+	flags := newFlagSet(env, "pipe", "")
+
+	var df dictionaryFlags
+	df.register(flags)
+
+	level := flags.String("level", "closest", "the suggestions to return: closest or all")
+	distance := flags.Int("distance", -1, "the maximum edit `distance` of suggestions, rather than the dictionary's")
+	maxResults := flags.Int("max", 0, "the maximum `number` of suggestions for each word, or 0 for no limit")
+	personal := flags.String("personal", "",
+		"the `file` holding the personal dictionary, which is created when it's first saved")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *level != "closest" && *level != "all" {
+		return fmt.Errorf("unknown suggestion level %q", *level)
+	}
+
+	lookupOpts, err := lookupOptions(&df, *level, *distance, *maxResults)
+	if err != nil {
+		return err
+	}
+
+	s, err := df.load()
+	if err != nil {
+		return err
+	}
+
+	p := &pipe{
+		overlay:  spell.NewOverlay(s),
+		personal: *personal,
+		opts: []spell.CheckOption{
+			spell.CheckLookupOpts(lookupOpts...),
+			spell.CheckKnownWords(),
+		},
+	}
+
+	if p.personal != "" {
+		overlay, err := spell.LoadOverlay(s, p.personal)
+		if err == nil {
+			p.overlay = overlay
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	p.opts = append(p.opts, spell.CheckOverlay(p.overlay))
+
+	w := bufio.NewWriter(env.stdout)
+	fmt.Fprintln(w, pipeBanner)
+
+	scanner := bufio.NewScanner(env.stdin)
+	scanner.Buffer(nil, 1<<20)
+
+	for scanner.Scan() {
+		if err := p.line(w, scanner.Text()); err != nil {
+			return err
+		}
+
+		// Clients wait for the response to each line before sending the next
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// pipe holds the state of a session of the pipe protocol.
+type pipe struct {
+	overlay  *spell.Overlay
+	personal string
+	opts     []spell.CheckOption
+	terse    bool
+}
+
+// line handles a line read, either running its command or checking it.
+func (p *pipe) line(w *bufio.Writer, line string) error {
+	if line == "" {
+		return p.check(w, line, 0)
+	}
+
+	arg := strings.TrimSpace(line[1:])
+
+	switch line[0] {
+	case '*':
+		return p.add(arg)
+	case '&':
+		return p.add(strings.ToLower(arg))
+	case '@':
+		if arg != "" {
+			p.overlay.IgnoreAll(arg)
+		}
+
+		return nil
+	case '#':
+		if p.personal == "" {
+			return nil
+		}
+
+		return p.overlay.Save(p.personal)
+	case '!':
+		p.terse = true
+
+		return nil
+	case '%':
+		p.terse = false
+
+		return nil
+	case '^':
+		return p.check(w, line[1:], 1)
+	case '+', '-', '~', '$':
+		return nil
+	}
+
+	return p.check(w, line, 0)
+}
+
+// add adds a word to the personal dictionary.
+func (p *pipe) add(word string) error {
+	if word == "" {
+		return nil
+	}
+
+	_, err := p.overlay.AddEntry(spell.Entry{Word: word, Frequency: 1})
+
+	return err
+}
+
+// check checks text, which begins offset characters into the line read.
+func (p *pipe) check(w *bufio.Writer, text string, offset int) error {
+	words, err := p.overlay.Spell().CheckText(text, p.opts...)
+	if err != nil {
+		return err
+	}
+
+	for _, word := range words {
+		switch {
+		case word.Known:
+			if !p.terse {
+				fmt.Fprintln(w, "*")
+			}
+		case len(word.Suggestions) == 0:
+			fmt.Fprintf(w, "# %s %d\n", word.Word, offset+word.RuneStart)
+		default:
+			fmt.Fprintf(w, "& %s %d %d: %s\n", word.Word, len(word.Suggestions), offset+word.RuneStart,
+				strings.Join(word.Suggestions.GetWords(), ", "))
+		}
+	}
+
+	fmt.Fprintln(w)
+
+	return nil
+}
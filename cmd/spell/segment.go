@@ -0,0 +1,97 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/eskriett/spell"
+)
+
+// segmentResult is the JSON output for a segmented input.
+type segmentResult struct {
+	Input         string                 `json:"input"`
+	Segmentations []*spell.SegmentResult `json:"segmentations"`
+}
+
+// runSegment divides text into words.
+func runSegment(env *env, args []string) error {
+	fs := newFlagSet(env, "segment", "[text ...]")
+
+	var df dictionaryFlags
+	df.register(fs)
+
+	n := fs.Int("n", 1, "the `number` of alternative segmentations to print")
+	bigrams := fs.Bool("bigrams", false, "score words using the dictionary's bigrams")
+	asJSON := fs.Bool("json", false, "print JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, err := df.load()
+	if err != nil {
+		return err
+	}
+
+	opts := []spell.SegmentOption{
+		spell.SegmentLookupOpts(
+			spell.DictionaryOpts(df.dictionaryOpts()...),
+			spell.SuggestionLevel(spell.LevelBest),
+		),
+	}
+
+	if *bigrams {
+		opts = append(opts, spell.SegmentBigrams())
+	}
+
+	// The arguments are segmented as a single input, otherwise each line of
+	// stdin is
+	inputs := []string{strings.Join(fs.Args(), " ")}
+	if fs.NArg() == 0 {
+		inputs = nil
+
+		scanner := bufio.NewScanner(env.stdin)
+		for scanner.Scan() {
+			inputs = append(inputs, scanner.Text())
+		}
+
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+	}
+
+	results := make([]segmentResult, 0, len(inputs))
+
+	for _, input := range inputs {
+		segmentations, err := s.SegmentN(input, *n, opts...)
+		if err != nil {
+			return err
+		}
+
+		results = append(results, segmentResult{Input: input, Segmentations: segmentations})
+	}
+
+	if *asJSON {
+		return writeJSON(env.stdout, results)
+	}
+
+	for _, result := range results {
+		if len(result.Segmentations) == 1 {
+			fmt.Fprintln(env.stdout, result.Segmentations[0])
+
+			continue
+		}
+
+		for i, segmentation := range result.Segmentations {
+			fmt.Fprintf(env.stdout, "%d. %s (distance %d, probability %g)\n",
+				i+1, segmentation, segmentation.Distance, segmentation.Probability)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/eskriett/spell"
+)
+
+// runStats describes a dictionary.
+func runStats(env *env, args []string) error {
+	fs := newFlagSet(env, "stats", "")
+
+	var df dictionaryFlags
+	df.register(fs)
+
+	asJSON := fs.Bool("json", false, "print JSON")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, err := df.load()
+	if err != nil {
+		return err
+	}
+
+	return printStats(env.stdout, s.Stats(), *asJSON)
+}
+
+// printStats prints the statistics of a dictionary.
+func printStats(w io.Writer, stats spell.Stats, asJSON bool) error {
+	if asJSON {
+		return writeJSON(w, stats)
+	}
+
+	fmt.Fprintf(w, "edit distance:        %d\n", stats.MaxEditDistance)
+	fmt.Fprintf(w, "prefix length:        %d\n", stats.PrefixLength)
+	fmt.Fprintf(w, "longest word:         %d\n", stats.LongestWord)
+	fmt.Fprintf(w, "cumulative frequency: %d\n", stats.CumulativeFrequency)
+
+	names := make([]string, 0, len(stats.Dictionaries))
+	for name := range stats.Dictionaries {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		dict := stats.Dictionaries[name]
+
+		_, err := fmt.Fprintf(w, "dictionary %q: %d words, frequency %d, %d deletes, %d n-grams\n",
+			name, dict.Words, dict.Frequency, dict.Deletes, dict.NGrams)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"context"
+	"strings"
+
+	"github.com/eskriett/spell/internal/textcase"
+)
+
+// CorrectionPolicy decides which misspellings CorrectText fixes. A misspelling
+// is fixed with its best suggestion only if every limit is met. Limits with a
+// zero value aren't applied.
+type CorrectionPolicy struct {
+	// The maximum edit distance of the best suggestion from the misspelling
+	MaxDistance int
+
+	// The minimum ratio of the frequency of the best suggestion to that of
+	// the next best suggestion
+	MinFrequencyRatio float64
+
+	// The minimum Probability, between 0 and 1, of the best suggestion being
+	// the word intended, relative to every word considered for the misspelling
+	MinConfidence float64
+}
+
+// accept reports whether the suggestions for a misspelling are good enough to
+// correct it with the first of them.
+func (p CorrectionPolicy) accept(suggestions SuggestionList) bool {
+	if len(suggestions) == 0 {
+		return false
+	}
+
+	best := suggestions[0]
+
+	if p.MaxDistance > 0 && best.Distance > p.MaxDistance {
+		return false
+	}
+
+	if p.MinFrequencyRatio > 0 && len(suggestions) > 1 {
+		next := float64(suggestions[1].Frequency)
+		if next > 0 && float64(best.Frequency)/next < p.MinFrequencyRatio {
+			return false
+		}
+	}
+
+	if p.MinConfidence > 0 && best.Probability < p.MinConfidence {
+		return false
+	}
+
+	return true
+}
+
+// Edit is a correction made by CorrectText.
+type Edit struct {
+	// The byte offsets of the corrected word within the original text
+	Start, End int
+
+	// The rune offsets of the corrected word within the original text
+	RuneStart, RuneEnd int
+
+	Original    string
+	Replacement string
+
+	// The suggestion used for the replacement
+	Suggestion Suggestion
+}
+
+// CorrectText checks text in the same way as CheckText, and replaces each
+// misspelling which the policy accepts with its best suggestion. Everything
+// else in the text, including whitespace and punctuation, is left unchanged.
+// The case of each replacement follows that of the misspelling, so that
+// capitalised and upper case words stay that way. Returns the corrected text
+// and the edits applied, in order.
+//
+// Accepts zero or more CheckOption that can be used to configure how words are
+// checked.
+func (s *Spell) CorrectText(text string, policy CorrectionPolicy, opts ...CheckOption) (string, []Edit, error) {
+	return s.CorrectTextContext(context.Background(), text, policy, opts...)
+}
+
+// CorrectTextContext is like CorrectText but stops once ctx is done. In that
+// case the text is returned with only the edits found so far applied, along
+// with an error wrapping both ErrInterrupted and the context's error.
+func (s *Spell) CorrectTextContext(ctx context.Context, text string, policy CorrectionPolicy,
+	opts ...CheckOption) (string, []Edit, error) {
+	// The confidence of a correction is the probability of its suggestion
+	if policy.MinConfidence > 0 {
+		opts = append(opts[:len(opts):len(opts)], func(cp *checkParams) error {
+			cp.lookupOptions = append(cp.lookupOptions[:len(cp.lookupOptions):len(cp.lookupOptions)],
+				Probabilities())
+
+			return nil
+		})
+	}
+
+	misspellings, checkErr := s.CheckTextContext(ctx, text, opts...)
+
+	var edits []Edit
+
+	var b strings.Builder
+
+	last := 0
+
+	for _, m := range misspellings {
+		if !policy.accept(m.Suggestions) {
+			continue
+		}
+
+		edit := Edit{
+			Start:       m.Start,
+			End:         m.End,
+			RuneStart:   m.RuneStart,
+			RuneEnd:     m.RuneEnd,
+			Original:    m.Word,
+			Replacement: textcase.Match(m.Word, m.Suggestions[0].Word),
+			Suggestion:  m.Suggestions[0],
+		}
+		edits = append(edits, edit)
+
+		b.WriteString(text[last:edit.Start])
+		b.WriteString(edit.Replacement)
+		last = edit.End
+	}
+
+	b.WriteString(text[last:])
+
+	return b.String(), edits, checkErr
+}
@@ -0,0 +1,108 @@
+package spell_test
+
+import (
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func TestCorrectText(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"the": 1000, "quick": 100, "brown": 100, "fox": 100, "box": 90,
+		"jumps": 50, "over": 100, "lazy": 50, "dog": 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "Teh  QUIKC brown fix,\tjumps ovr the lazzzzy dog."
+
+	corrected, edits, err := s.CorrectText(text, spell.CorrectionPolicy{MaxDistance: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "The  QUICK brown fox,\tjumps over the lazzzzy dog."
+	if corrected != expected {
+		t.Fatalf("expected %q, got %q", expected, corrected)
+	}
+
+	replacements := map[string]string{
+		"Teh": "The", "QUIKC": "QUICK", "fix": "fox", "ovr": "over",
+	}
+	if len(edits) != len(replacements) {
+		t.Fatalf("expected %d edits, got %+v", len(replacements), edits)
+	}
+
+	for _, edit := range edits {
+		if replacements[edit.Original] != edit.Replacement {
+			t.Errorf("expected %q to be replaced by %q, got %q",
+				edit.Original, replacements[edit.Original], edit.Replacement)
+		}
+		if text[edit.Start:edit.End] != edit.Original {
+			t.Errorf("offsets of %q give %q", edit.Original, text[edit.Start:edit.End])
+		}
+	}
+}
+
+func TestCorrectText_policy(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"fox": 100, "box": 90, "over": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		policy   spell.CorrectionPolicy
+		expected string
+	}{
+		{"no limits", spell.CorrectionPolicy{}, "fox over"},
+		{"distance", spell.CorrectionPolicy{MaxDistance: 1}, "fox ovvrr"},
+		{"frequency ratio", spell.CorrectionPolicy{MinFrequencyRatio: 2}, "ox over"},
+		{"confidence", spell.CorrectionPolicy{MinConfidence: 0.6}, "ox over"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			corrected, _, err := s.CorrectText("ox ovvrr", c.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if corrected != c.expected {
+				t.Fatalf("expected %q, got %q", c.expected, corrected)
+			}
+		})
+	}
+}
+
+func TestCorrectText_confidence(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"fox": 100, "on": 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Substitutions are made unlikely, so fox is the most probable correction
+	// of ox despite being less frequent than on
+	lookupOpts := spell.CheckLookupOpts(spell.RankByScore(), spell.ChannelModel(spell.EditProbabilities{
+		NoEdit: 0.95, Insert: 0.01, Delete: 0.01, Substitute: 0.001, Transpose: 0.01,
+	}))
+
+	corrected, edits, err := s.CorrectText("ox", spell.CorrectionPolicy{MinConfidence: 0.8}, lookupOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corrected != "fox" || len(edits) != 1 {
+		t.Fatalf("expected fox, got %q", corrected)
+	}
+	if p := edits[0].Suggestion.Probability; p < 0.8 {
+		t.Fatalf("expected a probability of at least 0.8, got %f", p)
+	}
+
+	corrected, _, err = s.CorrectText("ox", spell.CorrectionPolicy{MinConfidence: 0.9}, lookupOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if corrected != "ox" {
+		t.Fatalf("expected ox to be left unchanged, got %q", corrected)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// ReadEntries reads entries from r and adds them to the dictionary. Each line
+// holds a word, optionally followed by whitespace and its frequency, which is
+// 1 when omitted. The frequency is added to that of any existing entry for the
+// word, so both word lists with repeated words and frequency lists can be read.
+// Blank lines are skipped.
+func (s *Spell) ReadEntries(r io.Reader, opts ...DictionaryOption) error {
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if len(fields) > 2 {
+			return fmt.Errorf("line %d: expected a word and an optional frequency", line)
+		}
+
+		freq := uint64(1)
+
+		if len(fields) == 2 {
+			var err error
+
+			freq, err = strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid frequency: %w", line, err)
+			}
+		}
+
+		entry, err := s.GetEntry(fields[0], opts...)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if entry == nil {
+			entry = &Entry{Word: fields[0]}
+		}
+
+		entry.Frequency += freq
+
+		if _, err := s.AddEntry(*entry, opts...); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// WriteEntries writes each entry of the dictionary to w in the format read by
+// ReadEntries, most frequent first.
+func (s *Spell) WriteEntries(w io.Writer, opts ...DictionaryOption) error {
+	entries, err := s.Entries(opts...)
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	for _, entry := range entries {
+		if _, err := fmt.Fprintf(bw, "%s %d\n", entry.Word, entry.Frequency); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Entries returns every entry of the dictionary, ordered by descending
+// frequency and then by word.
+func (s *Spell) Entries(opts ...DictionaryOption) ([]Entry, error) {
+	dictOpts := s.defaultDictOptions()
+
+	for _, opt := range opts {
+		if err := opt(dictOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	entries := s.library.entries(dictOpts.name)
+	if entries == nil {
+		entries = []Entry{}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Frequency != entries[j].Frequency {
+			return entries[i].Frequency > entries[j].Frequency
+		}
+
+		return entries[i].Word < entries[j].Word
+	})
+
+	return entries, nil
+}
+
+// Stats describes the contents of a Spell.
+type Stats struct {
+	MaxEditDistance     uint32
+	PrefixLength        uint32
+	LongestWord         uint32
+	CumulativeFrequency uint64
+
+	// The statistics for each dictionary, by name
+	Dictionaries map[string]DictionaryStats
+}
+
+// DictionaryStats describes the contents of a dictionary.
+type DictionaryStats struct {
+	Words     uint64
+	Frequency uint64
+
+	// The number of distinct hashes of deletes indexing the dictionary's
+	// words
+	Deletes int
+
+	// The number of distinct bigrams and trigrams
+	NGrams int
+}
+
+// Stats returns statistics about the dictionaries of the Spell.
+func (s *Spell) Stats() Stats {
+	stats := Stats{
+		MaxEditDistance:     s.MaxEditDistance,
+		PrefixLength:        s.PrefixLength,
+		LongestWord:         atomic.LoadUint32(&s.longestWord),
+		CumulativeFrequency: atomic.LoadUint64(&s.cumulativeFreq),
+		Dictionaries:        make(map[string]DictionaryStats),
+	}
+
+	for dict, total := range s.library.totals() {
+		stats.Dictionaries[dict] = DictionaryStats{
+			Words:     total.words,
+			Frequency: total.frequency,
+		}
+	}
+
+	for dict, count := range s.dictionaryDeletes.counts() {
+		dictStats := stats.Dictionaries[dict]
+		dictStats.Deletes = count
+		stats.Dictionaries[dict] = dictStats
+	}
+
+	s.ngrams.RLock()
+	for dict, n := range s.ngrams.dictionaries {
+		dictStats := stats.Dictionaries[dict]
+		dictStats.NGrams = len(n.counts)
+		stats.Dictionaries[dict] = dictStats
+	}
+	s.ngrams.RUnlock()
+
+	return stats
+}
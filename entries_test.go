@@ -0,0 +1,66 @@
+package spell_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func TestReadWriteEntries(t *testing.T) {
+	s := spell.New()
+
+	if err := s.ReadEntries(strings.NewReader("the 100\n\nfox 10\nfox\ncat\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteEntries(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "the 100\nfox 11\ncat 1\n"; buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+
+	for _, input := range []string{"the many words 1\n", "the x\n"} {
+		if err := s.ReadEntries(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"the": 100, "fox": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.AddEntry(spell.Entry{Word: "colour", Frequency: 5}, spell.DictionaryName("british")); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := spell.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := read.Stats()
+	if stats.CumulativeFrequency != 115 || stats.LongestWord != 6 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	if dict := stats.Dictionaries["british"]; dict.Words != 1 || dict.Frequency != 5 || dict.Deletes == 0 {
+		t.Fatalf("unexpected stats for british dictionary %+v", dict)
+	}
+
+	if _, err := spell.Read(strings.NewReader("{")); err == nil {
+		t.Fatal("expected error for invalid dictionary")
+	}
+}
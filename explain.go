@@ -0,0 +1,101 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+type dropReason int
+
+// Drop Reasons describing why a suggestion was not returned by a Lookup.
+const (
+	// DropFurther is given when a closer suggestion was found.
+	DropFurther dropReason = iota
+
+	// DropLessFrequent is given when a more frequent suggestion was found at
+	// the same distance.
+	DropLessFrequent
+
+	// DropLowerScore is given when a suggestion with a higher score was found.
+	DropLowerScore
+
+	// DropMaxResults is given when the maximum number of results was reached
+	// with better suggestions.
+	DropMaxResults
+
+	// DropFiltered is given when a suggestion was excluded by a filter or
+	// minimum frequency.
+	DropFiltered
+)
+
+// String returns a description of the drop reason.
+func (r dropReason) String() string {
+	switch r {
+	case DropFurther:
+		return "a closer suggestion was found"
+	case DropLessFrequent:
+		return "a more frequent suggestion was found at the same distance"
+	case DropLowerScore:
+		return "a suggestion with a higher score was found"
+	case DropMaxResults:
+		return "the maximum number of results was reached"
+	case DropFiltered:
+		return "excluded by a filter"
+	}
+
+	return "unknown"
+}
+
+// Explanation describes how a suggestion was found during a Lookup.
+type Explanation struct {
+	// The candidate, formed by deleting runes from the input, that matched the
+	// suggestion. For an exact match, this is the input itself
+	Candidate string
+
+	// The edits which transform the input into the suggestion
+	Operations []EditOperation
+
+	// The suggestions considered during the lookup but not returned
+	Dropped []DroppedSuggestion
+}
+
+// DroppedSuggestion is a suggestion that was considered during a Lookup but not
+// returned, along with the reason why.
+type DroppedSuggestion struct {
+	Word       string
+	Dictionary string
+	Distance   int
+	Score      float64
+	Reason     dropReason
+}
+
+// Explain attaches an Explanation to each Suggestion returned by the Lookup.
+func Explain() LookupOption {
+	return func(lp *lookupParams) error {
+		lp.explain = true
+
+		return nil
+	}
+}
+
+// dropped returns a record of the suggestion having been dropped for reason.
+func (s Suggestion) dropped(reason dropReason) DroppedSuggestion {
+	return DroppedSuggestion{
+		Word:       s.Word,
+		Dictionary: s.Dictionary,
+		Distance:   s.Distance,
+		Score:      s.Score,
+		Reason:     reason,
+	}
+}
+
+// outranked returns the reason that loser was dropped in favour of winner.
+func outranked(winner, loser Suggestion, rankByScore bool) dropReason {
+	switch {
+	case rankByScore:
+		return DropLowerScore
+	case winner.Distance < loser.Distance:
+		return DropFurther
+	default:
+		return DropLessFrequent
+	}
+}
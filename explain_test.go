@@ -0,0 +1,112 @@
+package spell_test
+
+import (
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func TestLookup_explain(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"two": 100, "town": 1, "twin": 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s.Lookup("twon", spell.Explain())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Word != "two" {
+		t.Fatalf("expected two, got %v", suggestions)
+	}
+
+	explanation := suggestions[0].Explanation
+	if explanation == nil {
+		t.Fatal("expected an explanation")
+	}
+	if explanation.Candidate != "two" {
+		t.Fatalf("expected candidate two, got %s", explanation.Candidate)
+	}
+
+	ops := explanation.Operations
+	if len(ops) != 1 || ops[0].Type != spell.EditDelete || ops[0].Position != 3 ||
+		ops[0].From != "n" {
+		t.Fatalf("expected deletion of n at 3, got %+v", ops)
+	}
+
+	dropped := map[string]spell.DroppedSuggestion{}
+	for _, d := range explanation.Dropped {
+		dropped[d.Word] = d
+	}
+	if d, exists := dropped["twin"]; !exists || d.Reason != spell.DropLessFrequent {
+		t.Fatalf("expected twin to be dropped as less frequent, got %+v", explanation.Dropped)
+	}
+	if d, exists := dropped["town"]; !exists || d.Reason != spell.DropLessFrequent {
+		t.Fatalf("expected town to be dropped as less frequent, got %+v", explanation.Dropped)
+	}
+}
+
+func TestLookup_explainFurther(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"two": 1, "swan": 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, level := range []spell.LookupOption{
+		spell.SuggestionLevel(spell.LevelBest),
+		spell.SuggestionLevel(spell.LevelClosest),
+	} {
+		suggestions, err := s.Lookup("twon", spell.Explain(), level)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(suggestions) != 1 || suggestions[0].Word != "two" {
+			t.Fatalf("expected two, got %v", suggestions)
+		}
+
+		dropped := suggestions[0].Explanation.Dropped
+		if len(dropped) != 1 || dropped[0].Word != "swan" || dropped[0].Distance != 2 ||
+			dropped[0].Reason != spell.DropFurther || dropped[0].Score == 0 {
+			t.Fatalf("expected swan to be dropped as further, got %+v", dropped)
+		}
+	}
+}
+
+func TestLookup_explainTranspose(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"form": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s.Lookup("fomr", spell.Explain())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ops := suggestions[0].Explanation.Operations
+	if len(ops) != 1 || ops[0].Type != spell.EditTranspose || ops[0].Position != 2 ||
+		ops[0].From != "mr" || ops[0].To != "rm" {
+		t.Fatalf("expected transposition of mr at 2, got %+v", ops)
+	}
+}
+
+func TestLookup_explainFiltered(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"two": 100, "town": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s.Lookup("twon", spell.Explain(), spell.MinFrequency(10),
+		spell.SuggestionLevel(spell.LevelAll))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Word != "two" {
+		t.Fatalf("expected two, got %v", suggestions)
+	}
+
+	dropped := suggestions[0].Explanation.Dropped
+	if len(dropped) != 1 || dropped[0].Word != "town" || dropped[0].Reason != spell.DropFiltered {
+		t.Fatalf("expected town to be filtered, got %+v", dropped)
+	}
+}
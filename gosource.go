@@ -0,0 +1,348 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"context"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	gotoken "go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SplitIdentifier splits an identifier into the words it's made up of. Words
+// are separated by underscores, digits and changes in case, so that both
+// "getUsrName" and "get_usr_name" are split into "get", "usr" and "name" in
+// their original case. A run of upper case letters is kept together as an
+// acronym, so "HTTPServer" is split into "HTTP" and "Server".
+func SplitIdentifier(ident string) []string {
+	parts := identifierParts(ident)
+	words := make([]string, 0, len(parts))
+
+	for _, part := range parts {
+		words = append(words, ident[part[0]:part[1]])
+	}
+
+	return words
+}
+
+// identifierParts returns the byte offsets of the start and end of each word
+// within ident.
+func identifierParts(ident string) [][2]int {
+	var parts [][2]int
+
+	start := -1
+
+	var prev rune
+
+	var prevIndex int
+
+	for i, r := range ident {
+		switch {
+		case r == '_' || unicode.IsDigit(r):
+			if start >= 0 {
+				parts = append(parts, [2]int{start, i})
+				start = -1
+			}
+		case start < 0:
+			start = i
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			parts = append(parts, [2]int{start, i})
+			start = i
+		case unicode.IsLower(r) && unicode.IsUpper(prev) && prevIndex > start:
+			// The last letter of an acronym starts the next word
+			parts = append(parts, [2]int{start, prevIndex})
+			start = prevIndex
+		}
+
+		prev, prevIndex = r, i
+	}
+
+	if start >= 0 {
+		parts = append(parts, [2]int{start, len(ident)})
+	}
+
+	return parts
+}
+
+// splitIdentifiers splits each of the tokens into the words of an identifier.
+// Words of a single rune are dropped.
+func splitIdentifiers(tokens []token) []token {
+	split := make([]token, 0, len(tokens))
+
+	for _, t := range tokens {
+		for _, part := range identifierParts(t.word) {
+			word := t.word[part[0]:part[1]]
+
+			runes := utf8.RuneCountInString(word)
+			if runes < 2 {
+				continue
+			}
+
+			runeStart := t.runeStart + utf8.RuneCountInString(t.word[:part[0]])
+
+			split = append(split, token{
+				word:      word,
+				start:     t.start + part[0],
+				end:       t.start + part[1],
+				runeStart: runeStart,
+				runeEnd:   runeStart + runes,
+			})
+		}
+	}
+
+	return split
+}
+
+// SourceMisspelling is a misspelling within source code.
+type SourceMisspelling struct {
+	Misspelling
+
+	// The position of the start of the misspelling
+	Position gotoken.Position
+}
+
+// CheckGoSource checks the comments, string literals and identifiers of Go
+// source code. Identifiers, and words within comments and strings, are split
+// in the same way as SplitIdentifier, and each word of more than one rune is
+// checked. Identifiers are only checked where they're declared: by a func,
+// var, const or type declaration, as a parameter, result or field, by := or
+// as a label. So uses of identifiers, including predeclared identifiers and
+// those of imported packages, aren't checked. Import paths, the package name
+// and directives such as "//go:generate" aren't checked either. The filename
+// is used only for the position of each misspelling.
+//
+// If the source can't be scanned, the misspellings found are returned along
+// with a scanner.ErrorList.
+//
+// Accepts zero or more CheckOption that can be used to configure how words are
+// checked.
+func (s *Spell) CheckGoSource(filename string, src []byte, opts ...CheckOption) ([]SourceMisspelling, error) {
+	return s.CheckGoSourceContext(context.Background(), filename, src, opts...)
+}
+
+// CheckGoSourceContext is like CheckGoSource but stops once ctx is done, in the
+// same way as CheckTextContext.
+func (s *Spell) CheckGoSourceContext(ctx context.Context, filename string, src []byte,
+	opts ...CheckOption) ([]SourceMisspelling, error) {
+	fset := gotoken.NewFileSet()
+	file := fset.AddFile(filename, -1, len(src))
+
+	var errs scanner.ErrorList
+
+	var sc scanner.Scanner
+
+	sc.Init(file, src, errs.Add, scanner.ScanComments)
+
+	x := extractGo(&sc, file, string(src), declaredIdentifiers(filename, src))
+
+	misspellings, err := s.check(ctx, x.String(), func(text string) []token {
+		return splitIdentifiers(tokenize(text))
+	}, x.locate, opts)
+
+	results := make([]SourceMisspelling, 0, len(misspellings))
+	for _, m := range misspellings {
+		results = append(results, SourceMisspelling{
+			Misspelling: m,
+			Position:    file.Position(file.Pos(m.Start)),
+		})
+	}
+
+	if err != nil {
+		return results, err
+	}
+
+	errs.Sort()
+
+	return results, errs.Err()
+}
+
+// importState tracks whether the scanner is within an import declaration.
+type importState int
+
+const (
+	importNone importState = iota
+	importSpec
+	importGroup
+)
+
+// declaredIdentifiers returns the offsets of the identifiers declared within
+// the Go source src, other than those which shadow predeclared identifiers.
+// As much of the source is parsed as possible, even if it has errors.
+func declaredIdentifiers(filename string, src []byte) map[int]bool {
+	fset := gotoken.NewFileSet()
+
+	f, _ := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+	if f == nil {
+		return nil
+	}
+
+	declared := make(map[int]bool)
+
+	add := func(idents ...*ast.Ident) {
+		for _, ident := range idents {
+			if ident != nil && types.Universe.Lookup(ident.Name) == nil {
+				declared[fset.Position(ident.Pos()).Offset] = true
+			}
+		}
+	}
+
+	// addDefined adds the identifiers declared by := from exprs
+	addDefined := func(tok gotoken.Token, exprs ...ast.Expr) {
+		if tok != gotoken.DEFINE {
+			return
+		}
+
+		for _, expr := range exprs {
+			if ident, ok := expr.(*ast.Ident); ok {
+				add(ident)
+			}
+		}
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			add(n.Name)
+		case *ast.Field:
+			add(n.Names...)
+		case *ast.ValueSpec:
+			add(n.Names...)
+		case *ast.TypeSpec:
+			add(n.Name)
+		case *ast.AssignStmt:
+			addDefined(n.Tok, n.Lhs...)
+		case *ast.RangeStmt:
+			addDefined(n.Tok, n.Key, n.Value)
+		case *ast.LabeledStmt:
+			add(n.Label)
+		}
+
+		return true
+	})
+
+	return declared
+}
+
+// extractGo extracts the comments, string literals and declared identifiers of
+// Go source code scanned by sc.
+func extractGo(sc *scanner.Scanner, file *gotoken.File, source string, declared map[int]bool) *extractedText {
+	x := newExtractedText(source)
+
+	var state importState
+
+	for {
+		pos, tok, lit := sc.Scan()
+		if tok == gotoken.EOF {
+			break
+		}
+
+		offset := file.Offset(pos)
+
+		switch {
+		case tok == gotoken.IMPORT:
+			state = importSpec
+		case state != importNone:
+			switch tok {
+			case gotoken.LPAREN:
+				state = importGroup
+			case gotoken.RPAREN:
+				state = importNone
+			case gotoken.SEMICOLON:
+				if state == importSpec {
+					state = importNone
+				}
+			}
+		case tok == gotoken.IDENT:
+			if declared[offset] {
+				x.copy(offset, offset+len(lit))
+			}
+		case tok == gotoken.COMMENT:
+			if !strings.HasPrefix(lit, "//go:") && !strings.HasPrefix(lit, "//line ") {
+				x.copy(offset, commentEnd(source, offset))
+			}
+		case tok == gotoken.STRING:
+			end, closed := stringEnd(source, offset, lit)
+			x.goString(offset, end, closed)
+		}
+
+		x.separate(offset)
+	}
+
+	return x
+}
+
+// commentEnd returns the offset after the end of the comment at offset. The
+// scanner removes carriage returns from comments, so their length within the
+// source isn't known from their text.
+func commentEnd(source string, offset int) int {
+	if strings.HasPrefix(source[offset:], "/*") {
+		return min(indexFrom(source, offset+2, "*/")+2, len(source))
+	}
+
+	return indexFrom(source, offset, "\n")
+}
+
+// stringEnd returns the offset after the end of the string literal lit at
+// offset, and whether it's closed by a quote. Carriage returns are removed from
+// raw string literals too. An unterminated literal ends at the end of its
+// line, or of the source for a raw string literal.
+func stringEnd(source string, offset int, lit string) (int, bool) {
+	if strings.HasPrefix(lit, "`") {
+		end := indexFrom(source, offset+1, "`")
+		if end == len(source) {
+			return end, false
+		}
+
+		return end + 1, true
+	}
+
+	// The closing quote mustn't be escaped by an odd number of backslashes
+	body, closed := strings.CutSuffix(lit[1:], `"`)
+	closed = closed && (len(body)-len(strings.TrimRight(body, `\`)))%2 == 0
+
+	return offset + len(lit), closed
+}
+
+// goString adds the contents of the Go string literal between source offsets
+// start and end, decoding any escape sequences. If the literal isn't closed,
+// its contents extend to end.
+func (x *extractedText) goString(start, end int, closed bool) {
+	if closed {
+		end--
+	}
+
+	quote := x.source[start]
+	if quote == '`' {
+		x.copy(start+1, end)
+
+		return
+	}
+
+	for i := start + 1; i < end; {
+		rest := x.source[i:end]
+
+		value, _, tail, err := strconv.UnquoteChar(rest, quote)
+		if err != nil {
+			x.copy(i, end)
+
+			return
+		}
+
+		n := len(rest) - len(tail)
+		if x.source[i] == '\\' {
+			x.replace(string(value), i, i+n)
+		} else {
+			x.copy(i, i+n)
+		}
+
+		i += n
+	}
+}
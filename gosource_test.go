@@ -0,0 +1,127 @@
+package spell_test
+
+import (
+	"errors"
+	"go/scanner"
+	"reflect"
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func TestSplitIdentifier(t *testing.T) {
+	cases := map[string][]string{
+		"getUsrNmae":  {"get", "Usr", "Nmae"},
+		"max_retrys":  {"max", "retrys"},
+		"HTTPServer":  {"HTTP", "Server"},
+		"utf8Decode":  {"utf", "Decode"},
+		"ID":          {"ID"},
+		"_private":    {"private"},
+		"parseJSON2x": {"parse", "JSON", "x"},
+		"ÉtéCafé":     {"Été", "Café"},
+	}
+
+	for ident, expected := range cases {
+		if got := spell.SplitIdentifier(ident); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: expected %q, got %q", ident, expected, got)
+		}
+	}
+}
+
+func TestCheckGoSource(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"get": 100, "user": 100, "name": 100, "max": 100, "retries": 100,
+		"returns": 100, "the": 100, "of": 100, "hello": 100, "world": 100,
+		"string": 100, "fmt": 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := "package mian\n\n" +
+		"import (\n\tstrs \"strings\"\n\t\"net/http\"\n)\n\n" +
+		"//go:generate stringr\n\n" +
+		"// getUsrNmae returns the nme of the user.\n" +
+		"func getUsrNmae(max_retrys int) string {\n" +
+		"\treturn strs.ToUpper(\"hello\\twrld\") + http.MethodGet + `raw\r\nstrng`\n" +
+		"}\n\n" +
+		"type confg struct{ Nme string }\n\n" +
+		"var totl = len(getUsrNmae(1))\n\n" +
+		"func (c confg) get() {\n" +
+		"\tfor indx, valu := range c.Nme {\n" +
+		"\t\tmesage := indx + int(valu)\n" +
+		"\t\ttotl = mesage\n" +
+		"\t}\n" +
+		"}\n"
+
+	misspellings, err := s.CheckGoSource("main.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type misspelling struct {
+		word      string
+		line, col int
+	}
+
+	// Identifiers are only checked where they're declared
+	expected := []misspelling{
+		{"Usr", 10, 7}, {"Nmae", 10, 10}, {"nme", 10, 27},
+		{"Usr", 11, 9}, {"Nmae", 11, 12}, {"retrys", 11, 21},
+		{"wrld", 12, 30}, {"raw", 12, 57}, {"strng", 13, 1},
+		{"confg", 16, 6}, {"Nme", 16, 20}, {"totl", 18, 5},
+		{"indx", 21, 6}, {"valu", 21, 12}, {"mesage", 22, 3},
+	}
+
+	var got []misspelling
+	for _, m := range misspellings {
+		got = append(got, misspelling{m.Word, m.Position.Line, m.Position.Column})
+
+		if src[m.Start:m.End] != m.Word {
+			t.Errorf("offsets of %q give %q", m.Word, src[m.Start:m.End])
+		}
+		if m.Position.Filename != "main.go" || m.Position.Offset != m.Start {
+			t.Errorf("unexpected position %v for %q", m.Position, m.Word)
+		}
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestCheckGoSource_scanError(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"hello": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	misspellings, err := s.CheckGoSource("main.go", []byte("// helo\nvar s = \"unterminated\n"))
+
+	var errs scanner.ErrorList
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected scanner.ErrorList, got %v", err)
+	}
+	if len(misspellings) == 0 || misspellings[0].Word != "helo" {
+		t.Fatalf("expected \"helo\" to be misspelt, got %+v", misspellings)
+	}
+}
+
+func TestCheckGoSource_unterminated(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"hello": 100, "world": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, source := range []string{"var s = \"hello wrld\n", "var s = \"hello wrld", "var s = `hello wrld"} {
+		misspellings, err := s.CheckGoSource("main.go", []byte(source))
+		if err == nil {
+			t.Fatalf("%q: expected an error", source)
+		}
+
+		if len(misspellings) != 1 || misspellings[0].Word != "wrld" ||
+			source[misspellings[0].Start:misspellings[0].End] != "wrld" {
+			t.Fatalf("%q: expected \"wrld\" to be misspelt, got %+v", source, misspellings)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// IgnoreRule finds the parts of some text which aren't words, and so shouldn't
+// be checked or corrected. It returns the byte offsets of the start and end of
+// up to n parts of s, or of all of them if n is negative. A *regexp.Regexp is
+// an IgnoreRule.
+type IgnoreRule interface {
+	FindAllStringIndex(s string, n int) [][]int
+}
+
+// The rules making up the default rule pack.
+var (
+	// IgnoreURLs ignores URLs such as "https://example.com/a" and
+	// "www.example.com"
+	IgnoreURLs IgnoreRule = regexp.MustCompile(
+		`(?i)\b(?:[a-z][a-z0-9+.-]*://|www\.)[^\s<>"'` + "`" + `]*[^\s<>"'` + "`" + `.,;:!?)\]]`)
+
+	// IgnoreEmails ignores email addresses
+	IgnoreEmails IgnoreRule = regexp.MustCompile(
+		`[\p{L}\p{N}._%+-]+@[\p{L}\p{N}-]+(?:\.[\p{L}\p{N}-]+)+`)
+
+	// IgnoreHexHashes ignores hexadecimal numbers and hashes, such as
+	// "0xff" and "9fceb02", which contain at least one digit
+	IgnoreHexHashes IgnoreRule = filteredRule{
+		re:   regexp.MustCompile(`\b(?:0[xX][0-9a-fA-F]+|[0-9a-fA-F]{6,})\b`),
+		keep: hasDigit,
+	}
+
+	// IgnoreVersions ignores version numbers, such as "v1.2.3-beta.1"
+	IgnoreVersions IgnoreRule = regexp.MustCompile(
+		`[vV]?\d+(?:\.\d+)+(?:[-+][0-9A-Za-z]+(?:[.-][0-9A-Za-z]+)*)?`)
+
+	// IgnoreAcronyms ignores words of two or more letters which are all upper
+	// case, such as "NASA" and "APIs". It isn't part of the default rule pack,
+	// as it also ignores misspellings typed in upper case
+	IgnoreAcronyms IgnoreRule = wordRule(isAcronym)
+
+	// IgnoreNumbers ignores words which contain digits
+	IgnoreNumbers IgnoreRule = wordRule(hasDigit)
+)
+
+// DefaultIgnoreRules returns the default rule pack, which ignores URLs, email
+// addresses, hexadecimal numbers and hashes, version numbers and words
+// containing digits.
+func DefaultIgnoreRules() []IgnoreRule {
+	return []IgnoreRule{
+		IgnoreURLs,
+		IgnoreEmails,
+		IgnoreHexHashes,
+		IgnoreVersions,
+		IgnoreNumbers,
+	}
+}
+
+// filteredRule ignores the matches of a regular expression which are kept by
+// a filter.
+type filteredRule struct {
+	re   *regexp.Regexp
+	keep func(string) bool
+}
+
+// FindAllStringIndex implements IgnoreRule.
+func (r filteredRule) FindAllStringIndex(s string, n int) [][]int {
+	var matches [][]int
+
+	for _, match := range r.re.FindAllStringIndex(s, -1) {
+		if n >= 0 && len(matches) == n {
+			break
+		}
+
+		if r.keep(s[match[0]:match[1]]) {
+			matches = append(matches, match)
+		}
+	}
+
+	return matches
+}
+
+// wordRule ignores the words for which it returns true.
+type wordRule func(word string) bool
+
+// FindAllStringIndex implements IgnoreRule.
+func (r wordRule) FindAllStringIndex(s string, n int) [][]int {
+	var matches [][]int
+
+	for _, t := range tokenize(s) {
+		if n >= 0 && len(matches) == n {
+			break
+		}
+
+		if r(t.word) {
+			matches = append(matches, []int{t.start, t.end})
+		}
+	}
+
+	return matches
+}
+
+// hasDigit reports whether s contains a digit.
+func hasDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
+
+// isAcronym reports whether word has two or more letters, which are all upper
+// case other than an optional plural "s" following them.
+func isAcronym(word string) bool {
+	if stem, ok := strings.CutSuffix(word, "s"); ok {
+		last, _ := utf8.DecodeLastRuneInString(stem)
+		if !unicode.IsUpper(last) {
+			return false
+		}
+
+		word = stem
+	}
+
+	var letters int
+
+	for _, r := range word {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		if !unicode.IsUpper(r) {
+			return false
+		}
+
+		letters++
+	}
+
+	return letters >= 2
+}
+
+// ignoredSpans returns the byte offsets of the parts of text ignored by any of
+// the rules, ordered by their start, with overlapping parts merged.
+func ignoredSpans(text string, rules []IgnoreRule) [][2]int {
+	var spans [][2]int
+
+	for _, rule := range rules {
+		for _, match := range rule.FindAllStringIndex(text, -1) {
+			if match[0] < match[1] {
+				spans = append(spans, [2]int{match[0], match[1]})
+			}
+		}
+	}
+
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i][0] < spans[j][0]
+	})
+
+	merged := spans[:0]
+
+	for _, span := range spans {
+		if n := len(merged); n > 0 && span[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], span[1])
+
+			continue
+		}
+
+		merged = append(merged, span)
+	}
+
+	return merged
+}
+
+// removeIgnored returns the tokens, which must be in order, which don't
+// overlap any of the spans returned by ignoredSpans.
+func removeIgnored(tokens []token, spans [][2]int) []token {
+	if len(spans) == 0 {
+		return tokens
+	}
+
+	kept := make([]token, 0, len(tokens))
+
+	i := 0
+
+	for _, t := range tokens {
+		for i < len(spans) && spans[i][1] <= t.start {
+			i++
+		}
+
+		if i < len(spans) && spans[i][0] < t.end {
+			continue
+		}
+
+		kept = append(kept, t)
+	}
+
+	return kept
+}
@@ -0,0 +1,141 @@
+package spell_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func misspeltWords(misspellings []spell.Misspelling) []string {
+	words := make([]string, 0, len(misspellings))
+	for _, m := range misspellings {
+		words = append(words, m.Word)
+	}
+
+	return words
+}
+
+func TestCheckText_ignoreRules(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"see": 100, "or": 100, "mail": 100, "commit": 100, "in": 100,
+		"release": 100, "by": 100, "the": 100, "team": 100, "ticket": 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	text := "See https://exampel.com/pth?q=wrd or mail jon.doe@exampel.com: " +
+		"commit 9fceb02 in release v1.2.3-beta by the NASA team, ticket JIRA-42 abc123 teem"
+
+	cases := []struct {
+		name     string
+		opts     []spell.CheckOption
+		expected []string
+	}{
+		{"default", nil, []string{"NASA", "JIRA", "teem"}},
+		{"custom", []spell.CheckOption{spell.CheckIgnoreRules(append(spell.DefaultIgnoreRules(),
+			spell.IgnoreAcronyms, regexp.MustCompile(`\bteem\b`))...)}, nil},
+		{"acronyms only", []spell.CheckOption{spell.CheckIgnoreRules(spell.IgnoreAcronyms)},
+			[]string{"https", "exampel.com", "pth", "q", "wrd", "jon.doe", "exampel.com", "9fceb02",
+				"v1.2.3", "beta", "42", "abc123", "teem"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			misspellings, err := s.CheckText(text, c.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := misspeltWords(misspellings)
+			if len(got) != len(c.expected) {
+				t.Fatalf("expected %q, got %q", c.expected, got)
+			}
+			for i := range got {
+				if got[i] != c.expected[i] {
+					t.Fatalf("expected %q, got %q", c.expected, got)
+				}
+			}
+		})
+	}
+
+	if _, err := s.CheckText(text, spell.CheckIgnoreRules(nil)); err == nil {
+		t.Fatal("expected error for nil ignore rule")
+	}
+}
+
+func TestCheckText_ignoreAcronyms(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"the": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	misspellings, err := s.CheckText("NASA APIs TEH Ss sS ABCs ABCS AbC X",
+		spell.CheckIgnoreRules(spell.IgnoreAcronyms))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"Ss", "sS", "AbC", "X"}
+
+	got := misspeltWords(misspellings)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
+	}
+}
+
+func TestSegment_ignoreRules(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"mail": 100, "me": 100, "at": 100, "the": 100, "release": 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := "mailme at jon.doe@example.com, therelease v1.2.3"
+
+	result, err := s.Segment(input, spell.SegmentIgnoreRules(spell.IgnoreEmails, spell.IgnoreVersions))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "mail me at jon.doe@example.com, the release v1.2.3"
+	if result.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, result.String())
+	}
+	// Email addresses and hashes are passed through by default
+	s, err = newWithWords(map[string]uint64{
+		"bob": 100, "example": 100, "com": 100, "me": 100, "at": 100, "fix": 100,
+		"in": 100, "9": 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input = "mailbob@exmaple.com fixin 9fceb02a"
+
+	result, err = s.Segment(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "mailbob@exmaple.com fix in 9fceb02a"
+	if result.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, result.String())
+	}
+
+	// Unless no rules are given
+	result, err = s.Segment(input, spell.SegmentIgnoreRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() == expected {
+		t.Fatalf("expected %q to be segmented", input)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+// Package lines indexes the lines of some text, to convert between byte
+// offsets within the text and line based positions.
+package lines
+
+import "sort"
+
+// Index holds the byte offset of the start of each line of some text.
+type Index struct {
+	text   string
+	starts []int
+}
+
+// New indexes the lines of text, which are separated by "\n".
+func New(text string) *Index {
+	starts := []int{0}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+
+	return &Index{text: text, starts: starts}
+}
+
+// Text returns the text which was indexed.
+func (ix *Index) Text() string {
+	return ix.text
+}
+
+// Position returns the 0-based line containing offset, along with the text of
+// that line before offset.
+func (ix *Index) Position(offset int) (int, string) {
+	// The line is the last to start at or before offset
+	line := sort.SearchInts(ix.starts, offset+1) - 1
+
+	return line, ix.text[ix.starts[line]:offset]
+}
+
+// Start returns the byte offset of the start of the 0-based line, clamped to
+// the text.
+func (ix *Index) Start(line int) int {
+	if line < 0 {
+		return 0
+	} else if line >= len(ix.starts) {
+		return len(ix.text)
+	}
+
+	return ix.starts[line]
+}
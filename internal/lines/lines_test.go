@@ -0,0 +1,33 @@
+package lines_test
+
+import (
+	"testing"
+
+	"github.com/eskriett/spell/internal/lines"
+)
+
+func TestIndex(t *testing.T) {
+	ix := lines.New("ab\n\ncdé\n")
+
+	cases := []struct {
+		offset int
+		line   int
+		prefix string
+	}{
+		{0, 0, ""}, {2, 0, "ab"}, {3, 1, ""}, {4, 2, ""}, {8, 2, "cdé"}, {9, 3, ""},
+	}
+
+	for _, c := range cases {
+		line, prefix := ix.Position(c.offset)
+		if line != c.line || prefix != c.prefix {
+			t.Errorf("offset %d: expected line %d with %q, got line %d with %q",
+				c.offset, c.line, c.prefix, line, prefix)
+		}
+	}
+
+	for line, expected := range map[int]int{-1: 0, 0: 0, 1: 3, 2: 4, 3: 9, 4: 9} {
+		if got := ix.Start(line); got != expected {
+			t.Errorf("line %d: expected start %d, got %d", line, expected, got)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+// Package textcase matches the case of replacement words to the words they
+// replace.
+package textcase
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Match returns word with the case of original: upper case if original is
+// upper case, or capitalised if original is capitalised. Otherwise, word is
+// returned unchanged.
+func Match(original, word string) string {
+	var upper, lower int
+
+	for _, r := range original {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+
+	first, _ := utf8.DecodeRuneInString(original)
+
+	switch {
+	case upper > 1 && lower == 0:
+		return strings.ToUpper(word)
+	case unicode.IsUpper(first) && upper == 1:
+		r, size := utf8.DecodeRuneInString(word)
+
+		return string(unicode.ToUpper(r)) + word[size:]
+	}
+
+	return word
+}
@@ -0,0 +1,26 @@
+package textcase_test
+
+import (
+	"testing"
+
+	"github.com/eskriett/spell/internal/textcase"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		original, word, expected string
+	}{
+		{"teh", "the", "the"},
+		{"Teh", "the", "The"},
+		{"TEH", "the", "THE"},
+		{"tEh", "the", "the"},
+		{"Émile", "émile", "Émile"},
+		{"I", "a", "A"},
+	}
+
+	for _, c := range cases {
+		if got := textcase.Match(c.original, c.word); got != c.expected {
+			t.Errorf("Match(%q, %q): expected %q, got %q", c.original, c.word, c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,572 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+// Package lsp provides a Language Server Protocol server which reports the
+// misspellings in the documents open in an editor.
+//
+// The server publishes a diagnostic for each misspelling whenever a document
+// is opened or changed, and offers code actions which replace a misspelling
+// with one of its suggestions, add its word to the dictionary or ignore it. The
+// words added and ignored are held by a spell.Overlay, so the Spell being
+// served is never changed. Documents whose language is markdown, html or go
+// are checked with CheckMarkdown, CheckHTML and CheckGoSource respectively,
+// and any other document is checked as plain text.
+//
+// Messages are exchanged as JSON-RPC 2.0 over a stream, such as stdin and
+// stdout, each preceded by a Content-Length header. Positions are counted in
+// UTF-16 code units, as required by the protocol. The client may choose the
+// dictionary used with the "dictionary" initialization option.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/scanner"
+	"io"
+	"io/fs"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/eskriett/spell"
+	"github.com/eskriett/spell/internal/lines"
+	"github.com/eskriett/spell/internal/textcase"
+)
+
+// The commands executed by the code actions of the server.
+const (
+	// CommandAddToDictionary adds the word given as its argument to the
+	// overlay.
+	CommandAddToDictionary = "spell.addToDictionary"
+
+	// CommandIgnoreWord ignores every occurrence of the word given as its
+	// argument.
+	CommandIgnoreWord = "spell.ignoreWord"
+)
+
+// diagnosticSource is the source of the diagnostics published by the server.
+const diagnosticSource = "spell"
+
+// Server is a language server reporting misspellings. A Server serves a
+// single client.
+type Server struct {
+	overlay *spell.Overlay
+	params  *serverParams
+
+	docs map[string]*document
+	out  io.Writer
+
+	// Whether a shutdown request has been received
+	shutdown bool
+}
+
+// document is a text document open in the client.
+type document struct {
+	uri          string
+	languageID   string
+	version      int
+	text         string
+	lines        *lines.Index
+	misspellings []spell.Misspelling
+}
+
+type serverParams struct {
+	lookupOptions  []spell.LookupOption
+	maxSuggestions int
+	personalFile   string
+}
+
+// Option is a function that controls how the server checks documents. An
+// error will be returned if the Option is invalid.
+type Option func(*serverParams) error
+
+// LookupOpts sets the Lookup() options used for each word, such as the edit
+// distance or the dictionary used. By default, the closest suggestions from the
+// default dictionary are offered.
+func LookupOpts(opts ...spell.LookupOption) Option {
+	return func(sp *serverParams) error {
+		sp.lookupOptions = opts
+
+		return nil
+	}
+}
+
+// MaxSuggestions sets the maximum number of suggestions offered as code actions
+// for each misspelling. The default is 5.
+func MaxSuggestions(n int) Option {
+	return func(sp *serverParams) error {
+		if n < 1 {
+			return errors.New("max suggestions must be greater than 0")
+		}
+
+		sp.maxSuggestions = n
+
+		return nil
+	}
+}
+
+// PersonalDictionary loads the words added and ignored by the user from
+// filename, as saved by spell.Overlay.Save, and saves them there each time the
+// user adds or ignores a word. The file is created if it doesn't exist.
+func PersonalDictionary(filename string) Option {
+	return func(sp *serverParams) error {
+		if filename == "" {
+			return errors.New("personal dictionary filename must not be empty")
+		}
+
+		sp.personalFile = filename
+
+		return nil
+	}
+}
+
+// New creates a server which checks documents using s.
+//
+// Accepts zero or more Option that can be used to configure how documents are
+// checked.
+func New(s *spell.Spell, opts ...Option) (*Server, error) {
+	params := &serverParams{maxSuggestions: 5}
+
+	for _, opt := range opts {
+		if err := opt(params); err != nil {
+			return nil, err
+		}
+	}
+
+	overlay := spell.NewOverlay(s)
+
+	if params.personalFile != "" {
+		loaded, err := spell.LoadOverlay(s, params.personalFile)
+		if err == nil {
+			overlay = loaded
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	return &Server{
+		overlay: overlay,
+		params:  params,
+		docs:    make(map[string]*document),
+	}, nil
+}
+
+// Overlay returns the overlay holding the words added and ignored by the user.
+func (srv *Server) Overlay() *spell.Overlay {
+	return srv.overlay
+}
+
+// Serve reads requests from r and writes responses and notifications to w
+// until the client sends an exit notification or r is closed. Returns an error
+// if the client exits without first requesting a shutdown, or if a message
+// can't be read or written.
+func (srv *Server) Serve(r io.Reader, w io.Writer) error {
+	srv.out = w
+	br := bufio.NewReader(r)
+
+	for {
+		content, err := readMessage(br)
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var req request
+		if err := json.Unmarshal(content, &req); err != nil {
+			if err := srv.respondError(json.RawMessage("null"),
+				&rpcError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if req.Method == "exit" {
+			if !srv.shutdown {
+				return errors.New("exit without shutdown")
+			}
+
+			return nil
+		}
+
+		result, err := srv.handle(&req)
+
+		// Notifications aren't responded to, so their errors are logged by the
+		// client instead
+		if req.ID == nil {
+			if err != nil && !errors.Is(err, errMethodNotFound) {
+				err = srv.notify("window/logMessage", logMessageParams{
+					Type:    messageTypeError,
+					Message: fmt.Sprintf("%s: %v", req.Method, err),
+				})
+			}
+
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err != nil {
+			rerr := &rpcError{Code: codeInternalError, Message: err.Error()}
+
+			switch {
+			case errors.Is(err, errMethodNotFound):
+				rerr.Code = codeMethodNotFound
+			case errors.Is(err, errInvalidParams):
+				rerr.Code = codeInvalidParams
+			}
+
+			err = srv.respondError(*req.ID, rerr)
+		} else {
+			err = writeMessage(srv.out, response{JSONRPC: "2.0", ID: *req.ID, Result: result})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// errMethodNotFound is returned for a request the server doesn't support.
+var errMethodNotFound = errors.New("method not found")
+
+// handle handles a request, returning its result.
+func (srv *Server) handle(req *request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return srv.initialize(req.Params)
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		srv.shutdown = true
+
+		return nil, nil
+	case "textDocument/didOpen":
+		return nil, srv.didOpen(req.Params)
+	case "textDocument/didChange":
+		return nil, srv.didChange(req.Params)
+	case "textDocument/didClose":
+		return nil, srv.didClose(req.Params)
+	case "textDocument/codeAction":
+		return srv.codeAction(req.Params)
+	case "workspace/executeCommand":
+		return nil, srv.executeCommand(req.Params)
+	}
+
+	// Optional notifications, such as $/cancelRequest, may be ignored
+	if strings.HasPrefix(req.Method, "$/") {
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", errMethodNotFound, req.Method)
+}
+
+func (srv *Server) respondError(id json.RawMessage, rerr *rpcError) error {
+	return writeMessage(srv.out, errorResponse{JSONRPC: "2.0", ID: id, Error: rerr})
+}
+
+func (srv *Server) notify(method string, params interface{}) error {
+	return writeMessage(srv.out, notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (srv *Server) initialize(raw json.RawMessage) (interface{}, error) {
+	var params initializeParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	if name := params.InitializationOptions.Dictionary; name != "" {
+		srv.params.lookupOptions = append(srv.params.lookupOptions,
+			spell.DictionaryOpts(spell.DictionaryName(name)))
+	}
+
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"positionEncoding": "utf-16",
+			"textDocumentSync": map[string]interface{}{
+				"openClose": true,
+				"change":    syncFull,
+			},
+			"codeActionProvider": map[string]interface{}{
+				"codeActionKinds": []string{"quickfix"},
+			},
+			"executeCommandProvider": map[string]interface{}{
+				"commands": []string{CommandAddToDictionary, CommandIgnoreWord},
+			},
+		},
+		"serverInfo": map[string]interface{}{
+			"name": "spell",
+		},
+	}, nil
+}
+
+func (srv *Server) didOpen(raw json.RawMessage) error {
+	var params didOpenParams
+	if err := decodeParams(raw, &params); err != nil {
+		return err
+	}
+
+	item := params.TextDocument
+	doc := &document{uri: item.URI, languageID: item.LanguageID, version: item.Version}
+	srv.docs[item.URI] = doc
+
+	return srv.update(doc, item.Text)
+}
+
+func (srv *Server) didChange(raw json.RawMessage) error {
+	var params didChangeParams
+	if err := decodeParams(raw, &params); err != nil {
+		return err
+	}
+
+	doc, ok := srv.docs[params.TextDocument.URI]
+	if !ok || len(params.ContentChanges) == 0 {
+		return nil
+	}
+
+	// The server only supports full document sync, so the last change holds
+	// the whole text
+	doc.version = params.TextDocument.Version
+
+	return srv.update(doc, params.ContentChanges[len(params.ContentChanges)-1].Text)
+}
+
+func (srv *Server) didClose(raw json.RawMessage) error {
+	var params didCloseParams
+	if err := decodeParams(raw, &params); err != nil {
+		return err
+	}
+
+	delete(srv.docs, params.TextDocument.URI)
+
+	return srv.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         params.TextDocument.URI,
+		Diagnostics: []diagnostic{},
+	})
+}
+
+// update sets the text of a document, then checks it and publishes its
+// diagnostics.
+func (srv *Server) update(doc *document, text string) error {
+	doc.text = text
+	doc.lines = lines.New(text)
+
+	return srv.publish(doc)
+}
+
+// publish checks a document and publishes its diagnostics.
+func (srv *Server) publish(doc *document) error {
+	misspellings, err := srv.check(doc)
+	if err != nil {
+		return err
+	}
+
+	doc.misspellings = misspellings
+
+	diagnostics := make([]diagnostic, 0, len(misspellings))
+	for _, m := range misspellings {
+		diagnostics = append(diagnostics, doc.diagnostic(m))
+	}
+
+	return srv.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         doc.uri,
+		Version:     doc.version,
+		Diagnostics: diagnostics,
+	})
+}
+
+// check returns the misspellings within a document, checking it according to
+// its language.
+func (srv *Server) check(doc *document) ([]spell.Misspelling, error) {
+	lookupOpts := append([]spell.LookupOption{spell.SuggestionLevel(spell.LevelClosest)},
+		srv.params.lookupOptions...)
+	lookupOpts = append(lookupOpts, spell.MaxResults(srv.params.maxSuggestions))
+
+	opts := []spell.CheckOption{
+		spell.CheckOverlay(srv.overlay),
+		spell.CheckLookupOpts(lookupOpts...),
+	}
+
+	s := srv.overlay.Spell()
+
+	switch doc.languageID {
+	case "markdown":
+		return s.CheckMarkdown(doc.text, opts...)
+	case "html":
+		return s.CheckHTML(doc.text, opts...)
+	case "go":
+		// Documents being edited often can't be scanned, so scan errors are
+		// ignored
+		sourceMisspellings, err := s.CheckGoSource(documentPath(doc.uri), []byte(doc.text), opts...)
+
+		var scanErrs scanner.ErrorList
+		if err != nil && !errors.As(err, &scanErrs) {
+			return nil, err
+		}
+
+		misspellings := make([]spell.Misspelling, 0, len(sourceMisspellings))
+		for _, m := range sourceMisspellings {
+			misspellings = append(misspellings, m.Misspelling)
+		}
+
+		return misspellings, nil
+	}
+
+	return s.CheckText(doc.text, opts...)
+}
+
+// documentPath returns the path of a file URI, or the URI itself if it isn't
+// one.
+func documentPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+
+	return u.Path
+}
+
+// rangeOf returns the range of a misspelling within the document.
+func (doc *document) rangeOf(m spell.Misspelling) textRange {
+	return textRange{Start: positionOf(doc.lines, m.Start), End: positionOf(doc.lines, m.End)}
+}
+
+func (doc *document) diagnostic(m spell.Misspelling) diagnostic {
+	message := fmt.Sprintf("Unknown word %q", m.Word)
+	if len(m.Suggestions) > 0 {
+		message += fmt.Sprintf(", did you mean %s?", strings.Join(quoteWords(m.Suggestions.GetWords()), ", "))
+	}
+
+	return diagnostic{
+		Range:    doc.rangeOf(m),
+		Severity: severityInformation,
+		Source:   diagnosticSource,
+		Message:  message,
+	}
+}
+
+func quoteWords(words []string) []string {
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = fmt.Sprintf("%q", word)
+	}
+
+	return quoted
+}
+
+// codeAction returns the actions for each misspelling overlapping the range of
+// the request: replacing it with each of its suggestions, adding its word to
+// the dictionary and ignoring its word.
+func (srv *Server) codeAction(raw json.RawMessage) (interface{}, error) {
+	var params codeActionParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	actions := []codeAction{}
+
+	doc, ok := srv.docs[params.TextDocument.URI]
+	if !ok {
+		return actions, nil
+	}
+
+	start, end := offsetOf(doc.lines, params.Range.Start), offsetOf(doc.lines, params.Range.End)
+
+	for _, m := range doc.misspellings {
+		if m.End < start || m.Start > end {
+			continue
+		}
+
+		diag := doc.diagnostic(m)
+
+		for i, sugg := range m.Suggestions {
+			// Suggestions are lower case, so they're given the case of the
+			// misspelling, as CorrectText does
+			word := textcase.Match(m.Word, sugg.Word)
+
+			actions = append(actions, codeAction{
+				Title:       fmt.Sprintf("Change to %q", word),
+				Kind:        "quickfix",
+				Diagnostics: []diagnostic{diag},
+				IsPreferred: i == 0,
+				Edit: &workspaceEdit{Changes: map[string][]textEdit{
+					doc.uri: {{Range: diag.Range, NewText: word}},
+				}},
+			})
+		}
+
+		actions = append(actions, codeAction{
+			Title:       fmt.Sprintf("Add %q to dictionary", m.Word),
+			Kind:        "quickfix",
+			Diagnostics: []diagnostic{diag},
+			Command: &command{
+				Title:     "Add to dictionary",
+				Command:   CommandAddToDictionary,
+				Arguments: []interface{}{m.Word},
+			},
+		}, codeAction{
+			Title:       fmt.Sprintf("Ignore %q", m.Word),
+			Kind:        "quickfix",
+			Diagnostics: []diagnostic{diag},
+			Command: &command{
+				Title:     "Ignore",
+				Command:   CommandIgnoreWord,
+				Arguments: []interface{}{m.Word},
+			},
+		})
+	}
+
+	return actions, nil
+}
+
+// executeCommand adds or ignores a word, then publishes the diagnostics of
+// every open document again.
+func (srv *Server) executeCommand(raw json.RawMessage) error {
+	var params executeCommandParams
+	if err := decodeParams(raw, &params); err != nil {
+		return err
+	}
+
+	var word string
+	if len(params.Arguments) != 1 || json.Unmarshal(params.Arguments[0], &word) != nil || word == "" {
+		return fmt.Errorf("%w: %s expects a word", errInvalidParams, params.Command)
+	}
+
+	switch params.Command {
+	case CommandAddToDictionary:
+		if _, err := srv.overlay.AddEntry(spell.Entry{Word: word, Frequency: 1}); err != nil {
+			return err
+		}
+	case CommandIgnoreWord:
+		srv.overlay.IgnoreAll(word)
+	default:
+		return fmt.Errorf("%w: unknown command %q", errInvalidParams, params.Command)
+	}
+
+	if srv.params.personalFile != "" {
+		if err := srv.overlay.Save(srv.params.personalFile); err != nil {
+			return err
+		}
+	}
+
+	uris := make([]string, 0, len(srv.docs))
+	for uri := range srv.docs {
+		uris = append(uris, uri)
+	}
+
+	sort.Strings(uris)
+
+	for _, uri := range uris {
+		if err := srv.publish(srv.docs[uri]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
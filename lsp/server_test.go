@@ -0,0 +1,486 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/eskriett/spell"
+	"github.com/eskriett/spell/lsp"
+)
+
+func newSpell(t *testing.T) *spell.Spell {
+	t.Helper()
+
+	s := spell.New()
+
+	for word, freq := range map[string]uint64{"the": 1000, "quick": 100, "brown": 100, "fox": 100} {
+		if _, err := s.AddEntry(spell.Entry{Word: word, Frequency: freq}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s.AddEntry(spell.Entry{Word: "colour", Frequency: 10}, spell.DictionaryName("british")); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+// session holds the messages sent by a client.
+type session struct {
+	bytes.Buffer
+	id int
+}
+
+func (s *session) send(t *testing.T, id int, method string, params interface{}) {
+	t.Helper()
+
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != 0 {
+		msg["id"] = id
+	}
+
+	content, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fmt.Fprintf(s, "Content-Length: %d\r\n\r\n%s", len(content), content)
+}
+
+// request sends a request, returning its ID.
+func (s *session) request(t *testing.T, method string, params interface{}) int {
+	t.Helper()
+
+	s.id++
+	s.send(t, s.id, method, params)
+
+	return s.id
+}
+
+func (s *session) notify(t *testing.T, method string, params interface{}) {
+	t.Helper()
+
+	s.send(t, 0, method, params)
+}
+
+func (s *session) open(t *testing.T, uri, languageID, text string) {
+	t.Helper()
+
+	s.notify(t, "textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": languageID, "version": 1, "text": text},
+	})
+}
+
+// message is a message sent by the server.
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+type diagnostics struct {
+	URI         string `json:"uri"`
+	Diagnostics []struct {
+		Range   lspRange `json:"range"`
+		Message string   `json:"message"`
+	} `json:"diagnostics"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// serve runs a server for the messages of the session, returning the messages
+// sent by the server.
+func serve(t *testing.T, srv *lsp.Server, s *session) []message {
+	t.Helper()
+
+	s.request(t, "shutdown", nil)
+	s.notify(t, "exit", nil)
+
+	var out bytes.Buffer
+	if err := srv.Serve(&s.Buffer, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []message
+
+	r := bufio.NewReader(&out)
+
+	for {
+		header, err := textproto.NewReader(r).ReadMIMEHeader()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content := make([]byte, length)
+		if _, err := io.ReadFull(r, content); err != nil {
+			t.Fatal(err)
+		}
+
+		var msg message
+		if err := json.Unmarshal(content, &msg); err != nil {
+			t.Fatal(err)
+		}
+
+		messages = append(messages, msg)
+	}
+
+	return messages
+}
+
+// published returns the diagnostics published by the server, in order.
+func published(t *testing.T, messages []message) []diagnostics {
+	t.Helper()
+
+	var results []diagnostics
+
+	for _, msg := range messages {
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+
+		var d diagnostics
+		if err := json.Unmarshal(msg.Params, &d); err != nil {
+			t.Fatal(err)
+		}
+
+		results = append(results, d)
+	}
+
+	return results
+}
+
+// response returns the response to the request with the given ID.
+func response(t *testing.T, messages []message, id int) message {
+	t.Helper()
+
+	for _, msg := range messages {
+		if msg.ID != nil && *msg.ID == id && msg.Method == "" {
+			return msg
+		}
+	}
+
+	t.Fatalf("no response to request %d", id)
+
+	return message{}
+}
+
+func ranges(d diagnostics) []lspRange {
+	var results []lspRange
+	for _, diag := range d.Diagnostics {
+		results = append(results, diag.Range)
+	}
+
+	return results
+}
+
+func TestServer(t *testing.T) {
+	srv, err := lsp.New(newSpell(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const uri = "file:///tmp/a.txt"
+
+	var s session
+
+	initID := s.request(t, "initialize", map[string]interface{}{})
+	s.notify(t, "initialized", map[string]interface{}{})
+	s.open(t, uri, "plaintext", "😀 teh quick\nbrwn fox")
+	actionID := s.request(t, "textDocument/codeAction", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"range":        lspRange{Start: lspPosition{0, 4}, End: lspPosition{0, 4}},
+		"context":      map[string]interface{}{"diagnostics": []interface{}{}},
+	})
+	addID := s.request(t, "workspace/executeCommand", map[string]interface{}{
+		"command": lsp.CommandAddToDictionary, "arguments": []string{"brwn"},
+	})
+	s.notify(t, "textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]string{{"text": "the quikc fox"}},
+	})
+	s.notify(t, "textDocument/didClose", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	})
+	unknownID := s.request(t, "textDocument/hover", map[string]interface{}{})
+
+	messages := serve(t, srv, &s)
+
+	var initResult struct {
+		Capabilities struct {
+			PositionEncoding       string `json:"positionEncoding"`
+			ExecuteCommandProvider struct {
+				Commands []string `json:"commands"`
+			} `json:"executeCommandProvider"`
+		} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(response(t, messages, initID).Result, &initResult); err != nil {
+		t.Fatal(err)
+	}
+
+	if initResult.Capabilities.PositionEncoding != "utf-16" {
+		t.Errorf("expected utf-16 positions, got %q", initResult.Capabilities.PositionEncoding)
+	}
+
+	if len(initResult.Capabilities.ExecuteCommandProvider.Commands) != 2 {
+		t.Errorf("expected 2 commands, got %v", initResult.Capabilities.ExecuteCommandProvider.Commands)
+	}
+
+	all := published(t, messages)
+	if len(all) != 4 {
+		t.Fatalf("expected 4 publications, got %d", len(all))
+	}
+
+	// The emoji is two UTF-16 code units
+	expected := [][]lspRange{
+		{{lspPosition{0, 3}, lspPosition{0, 6}}, {lspPosition{1, 0}, lspPosition{1, 4}}},
+		{{lspPosition{0, 3}, lspPosition{0, 6}}},
+		{{lspPosition{0, 4}, lspPosition{0, 9}}},
+		nil,
+	}
+
+	for i, d := range all {
+		if d.URI != uri {
+			t.Errorf("expected diagnostics for %q, got %q", uri, d.URI)
+		}
+
+		if got := ranges(d); !reflect.DeepEqual(got, expected[i]) {
+			t.Errorf("publication %d: expected ranges %v, got %v", i, expected[i], got)
+		}
+	}
+
+	var actions []struct {
+		Title string `json:"title"`
+		Edit  *struct {
+			Changes map[string][]struct {
+				Range   lspRange `json:"range"`
+				NewText string   `json:"newText"`
+			} `json:"changes"`
+		} `json:"edit"`
+		Command *struct {
+			Command   string        `json:"command"`
+			Arguments []interface{} `json:"arguments"`
+		} `json:"command"`
+	}
+	if err := json.Unmarshal(response(t, messages, actionID).Result, &actions); err != nil {
+		t.Fatal(err)
+	}
+
+	var titles []string
+	for _, action := range actions {
+		titles = append(titles, action.Title)
+	}
+
+	expectedTitles := []string{`Change to "the"`, `Add "teh" to dictionary`, `Ignore "teh"`}
+	if !reflect.DeepEqual(titles, expectedTitles) {
+		t.Fatalf("expected actions %v, got %v", expectedTitles, titles)
+	}
+
+	edits := actions[0].Edit.Changes[uri]
+	if len(edits) != 1 || edits[0].NewText != "the" || edits[0].Range != expected[0][0] {
+		t.Errorf("unexpected edit %+v", edits)
+	}
+
+	if cmd := actions[1].Command; cmd.Command != lsp.CommandAddToDictionary ||
+		!reflect.DeepEqual(cmd.Arguments, []interface{}{"teh"}) {
+		t.Errorf("unexpected command %+v", cmd)
+	}
+
+	if resp := response(t, messages, addID); resp.Error != nil {
+		t.Errorf("unexpected error executing command: %+v", resp.Error)
+	}
+
+	if entry, _ := srv.Overlay().GetEntry("brwn"); entry == nil {
+		t.Error("expected word to be added to the overlay")
+	}
+
+	if resp := response(t, messages, unknownID); resp.Error == nil || resp.Error.Code != -32601 {
+		t.Errorf("expected method not found, got %+v", resp.Error)
+	}
+}
+
+func TestServer_codeActionCase(t *testing.T) {
+	srv, err := lsp.New(newSpell(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const uri = "file:///tmp/a.txt"
+
+	var s session
+
+	s.request(t, "initialize", map[string]interface{}{})
+	s.open(t, uri, "plaintext", "Teh quick TEH fox")
+
+	var ids []int
+	for _, char := range []int{1, 11} {
+		ids = append(ids, s.request(t, "textDocument/codeAction", map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri},
+			"range":        lspRange{Start: lspPosition{0, char}, End: lspPosition{0, char}},
+			"context":      map[string]interface{}{"diagnostics": []interface{}{}},
+		}))
+	}
+
+	messages := serve(t, srv, &s)
+
+	// Suggestions are given the case of the misspelling
+	for i, expected := range []string{"The", "THE"} {
+		var actions []struct {
+			Title string `json:"title"`
+			Edit  *struct {
+				Changes map[string][]struct {
+					NewText string `json:"newText"`
+				} `json:"changes"`
+			} `json:"edit"`
+		}
+		if err := json.Unmarshal(response(t, messages, ids[i]).Result, &actions); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(actions) == 0 || actions[0].Edit == nil {
+			t.Fatalf("expected a change action, got %+v", actions)
+		}
+
+		if title := fmt.Sprintf("Change to %q", expected); actions[0].Title != title {
+			t.Errorf("expected title %q, got %q", title, actions[0].Title)
+		}
+
+		if edits := actions[0].Edit.Changes[uri]; len(edits) != 1 || edits[0].NewText != expected {
+			t.Errorf("expected the edit to insert %q, got %+v", expected, edits)
+		}
+	}
+}
+
+func TestServer_languages(t *testing.T) {
+	srv, err := lsp.New(newSpell(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s session
+
+	s.request(t, "initialize", map[string]interface{}{})
+	s.open(t, "file:///tmp/a.md", "markdown", "The `qick` **brwn** fox\n")
+	s.open(t, "file:///tmp/a.html", "html", `<p class="qick">The <b>brwn</b> fox</p>`)
+	s.open(t, "file:///tmp/a.go", "go", "package main\n\n// The brwn fox\nfunc qickFox() {")
+
+	all := published(t, serve(t, srv, &s))
+	if len(all) != 3 {
+		t.Fatalf("expected 3 publications, got %d", len(all))
+	}
+
+	// Code spans and attributes aren't checked, and scan errors in Go source
+	// are ignored
+	expected := map[string]int{"file:///tmp/a.md": 1, "file:///tmp/a.html": 1, "file:///tmp/a.go": 2}
+
+	for _, d := range all {
+		if len(d.Diagnostics) != expected[d.URI] {
+			t.Errorf("%s: expected %d diagnostics, got %+v", d.URI, expected[d.URI], d.Diagnostics)
+		}
+	}
+}
+
+func TestServer_dictionary(t *testing.T) {
+	srv, err := lsp.New(newSpell(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s session
+
+	s.request(t, "initialize", map[string]interface{}{
+		"initializationOptions": map[string]string{"dictionary": "british"},
+	})
+	s.open(t, "file:///tmp/a.txt", "plaintext", "colour color")
+
+	all := published(t, serve(t, srv, &s))
+	if len(all) != 1 || len(all[0].Diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %+v", all)
+	}
+
+	if got := all[0].Diagnostics[0].Range.Start.Character; got != 7 {
+		t.Errorf("expected misspelling at 7, got %d", got)
+	}
+}
+
+func TestServer_personalDictionary(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "personal.json")
+
+	srv, err := lsp.New(newSpell(t), lsp.PersonalDictionary(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s session
+
+	s.request(t, "initialize", map[string]interface{}{})
+	s.request(t, "workspace/executeCommand", map[string]interface{}{
+		"command": lsp.CommandIgnoreWord, "arguments": []string{"brwn"},
+	})
+	serve(t, srv, &s)
+
+	srv, err = lsp.New(newSpell(t), lsp.PersonalDictionary(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !srv.Overlay().Ignored("brwn") {
+		t.Error("expected ignored word to be loaded")
+	}
+}
+
+func TestServer_exitWithoutShutdown(t *testing.T) {
+	srv, err := lsp.New(newSpell(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s session
+
+	s.notify(t, "exit", nil)
+
+	if err := srv.Serve(&s.Buffer, io.Discard); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestServer_contentLength(t *testing.T) {
+	srv, err := lsp.New(newSpell(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, length := range []string{"-1", "x", "1000000000"} {
+		in := bytes.NewBufferString("Content-Length: " + length + "\r\n\r\n{}")
+		if err := srv.Serve(in, io.Discard); err == nil {
+			t.Errorf("expected an error for Content-Length %s", length)
+		}
+	}
+}
@@ -0,0 +1,552 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// CheckHTML is like CheckText, but checks only the text of an HTML document.
+// Tags, attributes, comments, scripts, styles and the contents of code, kbd,
+// pre, samp and var elements are skipped, and character references are
+// decoded. The offsets of each misspelling are within the HTML source.
+func (s *Spell) CheckHTML(source string, opts ...CheckOption) ([]Misspelling, error) {
+	return s.CheckHTMLContext(context.Background(), source, opts...)
+}
+
+// CheckHTMLContext is like CheckHTML but stops once ctx is done, in the same way
+// as CheckTextContext.
+func (s *Spell) CheckHTMLContext(ctx context.Context, source string, opts ...CheckOption) ([]Misspelling, error) {
+	x := extractHTML(source)
+
+	return s.check(ctx, x.String(), tokenize, x.locate, opts)
+}
+
+// CheckMarkdown is like CheckText, but checks only the text of a CommonMark
+// document. Code blocks, code spans, link destinations, link reference
+// definitions and HTML tags are skipped, and escapes and character references
+// are decoded. The offsets of each misspelling are within the Markdown source.
+func (s *Spell) CheckMarkdown(source string, opts ...CheckOption) ([]Misspelling, error) {
+	return s.CheckMarkdownContext(context.Background(), source, opts...)
+}
+
+// CheckMarkdownContext is like CheckMarkdown but stops once ctx is done, in the
+// same way as CheckTextContext.
+func (s *Spell) CheckMarkdownContext(ctx context.Context, source string, opts ...CheckOption) ([]Misspelling, error) {
+	x := extractMarkdown(source)
+
+	return s.check(ctx, x.String(), tokenize, x.locate, opts)
+}
+
+// extractedText is the readable text of some markup, along with the location
+// within the markup of each byte of the text.
+type extractedText struct {
+	source string
+	text   []byte
+
+	// The offsets within the source of the start and end of each byte
+	starts, ends []int
+
+	// The source offsets of the last located token, which is used to count
+	// runes from
+	byteOffset, runeOffset int
+}
+
+func newExtractedText(source string) *extractedText {
+	return &extractedText{
+		source: source,
+		text:   make([]byte, 0, len(source)),
+		starts: make([]int, 0, len(source)),
+		ends:   make([]int, 0, len(source)),
+	}
+}
+
+// String returns the extracted text.
+func (x *extractedText) String() string {
+	return string(x.text)
+}
+
+// copy adds source[start:end] to the text.
+func (x *extractedText) copy(start, end int) {
+	for i := start; i < end; i++ {
+		x.text = append(x.text, x.source[i])
+		x.starts = append(x.starts, i)
+		x.ends = append(x.ends, i+1)
+	}
+}
+
+// replace adds s to the text in place of source[start:end].
+func (x *extractedText) replace(s string, start, end int) {
+	for i := 0; i < len(s); i++ {
+		x.text = append(x.text, s[i])
+		x.starts = append(x.starts, start)
+		x.ends = append(x.ends, end)
+	}
+}
+
+// separate ensures that words before and after source offset pos aren't
+// joined.
+func (x *extractedText) separate(pos int) {
+	if n := len(x.text); n > 0 && x.text[n-1] == '\n' {
+		return
+	}
+
+	x.replace("\n", pos, pos)
+}
+
+// entity adds the character reference at source offset i to the text, and
+// returns the offset after it. If there's no character reference at i, only
+// the ampersand is added.
+func (x *extractedText) entity(i, end int) int {
+	const maxEntityLength = 32
+
+	j := i + 1
+	for j < end && j-i < maxEntityLength && isEntityByte(x.source[j]) {
+		j++
+	}
+
+	if j < end && j > i+1 && x.source[j] == ';' {
+		ref := x.source[i : j+1]
+		if decoded := html.UnescapeString(ref); decoded != ref {
+			x.replace(decoded, i, j+1)
+
+			return j + 1
+		}
+	}
+
+	x.copy(i, i+1)
+
+	return i + 1
+}
+
+// locate maps the offsets of a token from the text to the source. Tokens must
+// be located in order.
+func (x *extractedText) locate(t token) token {
+	start, end := x.starts[t.start], x.ends[t.end-1]
+
+	x.runeOffset += utf8.RuneCountInString(x.source[x.byteOffset:start])
+	x.byteOffset = start
+
+	t.start, t.end = start, end
+	t.runeStart = x.runeOffset
+	t.runeEnd = x.runeOffset + utf8.RuneCountInString(x.source[start:end])
+
+	return t
+}
+
+func isEntityByte(c byte) bool {
+	return c == '#' || isASCIILetter(c) || ('0' <= c && c <= '9')
+}
+
+func isASCIILetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// indexFrom returns the index of the first instance of substr in s at or after
+// offset i, or len(s) if there is none.
+func indexFrom(s string, i int, substr string) int {
+	if j := strings.Index(s[i:], substr); j >= 0 {
+		return i + j
+	}
+
+	return len(s)
+}
+
+// indexClosingTag returns the offset of the first closing tag for the element
+// name in source at or after offset i, or len(source) if there is none.
+func indexClosingTag(source string, i int, name string) int {
+	for {
+		i = indexFrom(source, i, "</")
+		if i == len(source) {
+			return i
+		}
+
+		if tagName := source[i+2 : min(i+2+len(name), len(source))]; strings.EqualFold(tagName, name) {
+			return i
+		}
+
+		i += 2
+	}
+}
+
+// htmlRawText are the elements whose content isn't parsed as HTML.
+var htmlRawText = map[string]bool{
+	"script": true, "style": true,
+}
+
+// htmlSkipped are the elements whose content isn't checked.
+var htmlSkipped = map[string]bool{
+	"code": true, "kbd": true, "pre": true, "samp": true, "var": true,
+}
+
+// htmlInline are the elements which may be within a word.
+var htmlInline = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "cite": true,
+	"dfn": true, "em": true, "i": true, "mark": true, "q": true, "s": true,
+	"small": true, "span": true, "strong": true, "sub": true, "sup": true,
+	"time": true, "u": true,
+}
+
+// htmlTag is a tag within an HTML document.
+type htmlTag struct {
+	name        string
+	end         int
+	closing     bool
+	selfClosing bool
+}
+
+// scanTag scans the tag, comment or declaration starting at offset i of
+// source. ok is false if there's none.
+func scanTag(source string, i int) (tag htmlTag, ok bool) {
+	if i+1 >= len(source) {
+		return tag, false
+	}
+
+	j := i + 1
+
+	switch c := source[j]; {
+	case strings.HasPrefix(source[j:], "!--"):
+		tag.end = indexFrom(source, j+3, "-->")
+		tag.end = min(tag.end+len("-->"), len(source))
+
+		return tag, true
+	case c == '!' || c == '?':
+		tag.end = min(indexFrom(source, j, ">")+1, len(source))
+
+		return tag, true
+	case c == '/':
+		tag.closing = true
+		j++
+	}
+
+	nameStart := j
+	for j < len(source) && (isASCIILetter(source[j]) || ('0' <= source[j] && source[j] <= '9') ||
+		source[j] == '-') {
+		j++
+	}
+
+	if j == nameStart || !isASCIILetter(source[nameStart]) {
+		return tag, false
+	}
+
+	tag.name = strings.ToLower(source[nameStart:j])
+
+	// Attribute values may contain '>'
+	for quote := byte(0); j < len(source); j++ {
+		switch c := source[j]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			tag.selfClosing = source[j-1] == '/'
+			tag.end = j + 1
+
+			return tag, true
+		}
+	}
+
+	tag.end = len(source)
+
+	return tag, true
+}
+
+// extractHTML extracts the readable text of an HTML document.
+func extractHTML(source string) *extractedText {
+	x := newExtractedText(source)
+
+	// The element whose content is being skipped, and how deeply it's nested
+	var skipped string
+
+	var depth int
+
+	for i := 0; i < len(source); {
+		if source[i] == '<' {
+			if tag, ok := scanTag(source, i); ok {
+				switch {
+				case skipped != "":
+					if tag.name == skipped && tag.closing {
+						depth--
+					} else if tag.name == skipped && !tag.selfClosing {
+						depth++
+					}
+
+					if depth == 0 {
+						skipped = ""
+					}
+				case htmlRawText[tag.name] && !tag.closing && !tag.selfClosing:
+					tag.end = indexClosingTag(source, tag.end, tag.name)
+				case htmlSkipped[tag.name] && !tag.closing && !tag.selfClosing:
+					skipped, depth = tag.name, 1
+				}
+
+				if !htmlInline[tag.name] {
+					x.separate(i)
+				}
+
+				i = tag.end
+
+				continue
+			}
+		}
+
+		switch {
+		case skipped != "":
+			i++
+		case source[i] == '&':
+			i = x.entity(i, len(source))
+		default:
+			x.copy(i, i+1)
+			i++
+		}
+	}
+
+	return x
+}
+
+// extractMarkdown extracts the readable text of a CommonMark document.
+func extractMarkdown(source string) *extractedText {
+	x := newExtractedText(source)
+
+	// The fence of the current fenced code block, and whether the current
+	// line is within an indented code block
+	var fence string
+
+	var indented bool
+
+	prevBlank := true
+
+	// The source offsets of the inline Markdown of the current paragraph,
+	// which is added once the paragraph ends so that code spans may cross
+	// line breaks
+	paraStart, paraEnd := -1, -1
+
+	flush := func() {
+		if paraStart >= 0 {
+			x.markdownInline(paraStart, paraEnd)
+			x.separate(paraEnd)
+			paraStart = -1
+		}
+	}
+
+	for start := 0; start < len(source); {
+		end := indexFrom(source, start, "\n")
+		line := strings.TrimSuffix(source[start:end], "\r")
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		blank := strings.TrimSpace(line) == ""
+		inline := false
+
+		switch {
+		case fence != "":
+			if indent < 4 && strings.HasPrefix(trimmed, fence) &&
+				strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "" {
+				fence = ""
+			}
+		case indent < 4 && (strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")):
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+		case (indent >= 4 || strings.HasPrefix(line, "\t")) && (prevBlank || indented):
+			indented = true
+		case indented && blank:
+		case indent < 4 && isLinkDefinition(trimmed):
+			indented = false
+		case blank:
+			indented = false
+		default:
+			indented = false
+
+			// A heading is a paragraph of its own
+			heading := strings.HasPrefix(trimmed, "#")
+			if heading {
+				flush()
+			}
+
+			if paraStart < 0 {
+				paraStart = start + indent
+			}
+
+			paraEnd = start + len(line)
+			inline = !heading
+		}
+
+		if !inline {
+			flush()
+			x.separate(end)
+		}
+
+		prevBlank = blank
+		start = end + 1
+	}
+
+	flush()
+
+	return x
+}
+
+// isLinkDefinition reports whether line is a link reference definition.
+func isLinkDefinition(line string) bool {
+	if !strings.HasPrefix(line, "[") {
+		return false
+	}
+
+	i := strings.Index(line, "]:")
+
+	return i > 1 && !strings.Contains(line[:i], "]")
+}
+
+// markdownInline adds the readable text of the inline Markdown between source
+// offsets start and end.
+func (x *extractedText) markdownInline(start, end int) {
+	source := x.source
+
+	for i := start; i < end; {
+		c := source[i]
+
+		switch {
+		case c == '\\' && i+1 < end && isASCIIPunct(source[i+1]):
+			x.replace(source[i+1:i+2], i, i+2)
+			i += 2
+		case c == '`':
+			// A code span ends with a backtick string of the same length. An
+			// unmatched backtick string is literal
+			n := backticks(source[i:end])
+			j := i + n
+
+			for k := j; k < end; {
+				k = indexFrom(source[:end], k, "`")
+				if k == end {
+					break
+				}
+
+				m := backticks(source[k:end])
+				if m == n {
+					j = k + m
+
+					break
+				}
+
+				k += m
+			}
+
+			x.separate(i)
+			i = j
+		case c == '<':
+			tag, ok := scanTag(source[:end], i)
+			if !ok && i+1 < end && isASCIILetter(source[i+1]) {
+				// An autolink, such as <https://example.com>, which can't
+				// cross a line break
+				lineEnd := indexFrom(source[:end], i, "\n")
+				tag.end, ok = min(indexFrom(source[:lineEnd], i, ">")+1, lineEnd), true
+			}
+
+			if !ok {
+				x.copy(i, i+1)
+				i++
+
+				continue
+			}
+
+			x.separate(i)
+			i = tag.end
+		case c == ']' && i+1 < end && (source[i+1] == '(' || source[i+1] == '['):
+			// The destination of an inline link, or the label of a
+			// reference link
+			open, close := source[i+1], byte(')')
+			if open == '[' {
+				close = ']'
+			}
+
+			j, depth := i+2, 1
+			for ; j < end && depth > 0; j++ {
+				switch source[j] {
+				case open:
+					depth++
+				case close:
+					depth--
+				}
+			}
+
+			x.separate(i)
+			i = j
+		case c == '&':
+			i = x.entity(i, end)
+		case c == '*' || c == '_' || c == '~':
+			i = x.emphasis(i, start, end)
+		default:
+			x.copy(i, i+1)
+			i++
+		}
+	}
+}
+
+// emphasis adds the text of the run of emphasis delimiters at source offset i,
+// between source offsets start and end, and returns the offset after it. As in
+// CommonMark, a run is only a delimiter if it's left or right flanking, and an
+// underscore can't open or close emphasis within a word. A delimiter within a
+// word is removed without separating the text either side of it, while other
+// runs are kept as literal text.
+func (x *extractedText) emphasis(i, start, end int) int {
+	source := x.source
+	c := source[i]
+
+	j := i
+	for j < end && source[j] == c {
+		j++
+	}
+
+	before, after := ' ', ' '
+	if i > start {
+		before, _ = utf8.DecodeLastRuneInString(source[:i])
+	}
+
+	if j < end {
+		after, _ = utf8.DecodeRuneInString(source[j:])
+	}
+
+	leftFlanking := !unicode.IsSpace(after) &&
+		(!isPunctuation(after) || unicode.IsSpace(before) || isPunctuation(before))
+	rightFlanking := !unicode.IsSpace(before) &&
+		(!isPunctuation(before) || unicode.IsSpace(after) || isPunctuation(after))
+
+	canOpen, canClose := leftFlanking, rightFlanking
+	if c == '_' {
+		canOpen = leftFlanking && (!rightFlanking || isPunctuation(before))
+		canClose = rightFlanking && (!leftFlanking || isPunctuation(after))
+	}
+
+	switch {
+	case !canOpen && !canClose:
+		x.copy(i, j)
+	case !(leftFlanking && rightFlanking):
+		x.separate(i)
+	}
+
+	return j
+}
+
+// isPunctuation reports whether r is punctuation or a symbol, as CommonMark
+// defines when deciding whether a delimiter run is flanking.
+func isPunctuation(r rune) bool {
+	if r < utf8.RuneSelf {
+		return isASCIIPunct(byte(r))
+	}
+
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// backticks returns the number of backticks at the start of s.
+func backticks(s string) int {
+	return len(s) - len(strings.TrimLeft(s, "`"))
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
@@ -0,0 +1,124 @@
+package spell_test
+
+import (
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func newMarkupSpell(t *testing.T) *spell.Spell {
+	t.Helper()
+
+	s, err := newWithWords(map[string]uint64{
+		"the": 1000, "quick": 100, "brown": 100, "fox": 100, "jumps": 50,
+		"over": 100, "lazy": 50, "dog": 100, "café": 10, "see": 10, "bold": 10,
+		"link": 10, "text": 10, "title": 10, "an": 10, "image": 10, "and": 10,
+		"escaped": 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func checkMisspellings(t *testing.T, source string, misspellings []spell.Misspelling,
+	expected map[string]string) {
+	t.Helper()
+
+	if len(misspellings) != len(expected) {
+		t.Fatalf("expected %d misspellings, got %+v", len(expected), misspellings)
+	}
+
+	runes := []rune(source)
+
+	for _, m := range misspellings {
+		original, ok := expected[m.Word]
+		if !ok {
+			t.Errorf("unexpected misspelling %q", m.Word)
+
+			continue
+		}
+		if source[m.Start:m.End] != original {
+			t.Errorf("expected offsets of %q to give %q, got %q", m.Word, original, source[m.Start:m.End])
+		}
+		if string(runes[m.RuneStart:m.RuneEnd]) != original {
+			t.Errorf("expected rune offsets of %q to give %q, got %q", m.Word, original,
+				string(runes[m.RuneStart:m.RuneEnd]))
+		}
+	}
+}
+
+func TestCheckHTML(t *testing.T) {
+	s := newMarkupSpell(t)
+
+	source := `<!DOCTYPE html>
+<html><head><title>The quikc title</title>
+<style>p { colr: red; }</style>
+<script>if (a < b) { documnt.write("x") }</script></head>
+<body class="brwn">
+<!-- a commnt -->
+<p title="ttle">Thé <b>bro</b>wn fox jumpd ovér the <a href="/lazzy">lazy</a> dog.</p>
+<p>Café, caf&eacute; and cafe&#769;s</p>
+<pre>fmt.Prntln(<code>x</code>) jumpd</pre><p>see</p>
+</body></html>`
+
+	misspellings, err := s.CheckHTML(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkMisspellings(t, source, misspellings, map[string]string{
+		"quikc":       "quikc",
+		"Thé":         "Thé",
+		"jumpd":       "jumpd",
+		"ovér":        "ovér",
+		"cafe\u0301s": "cafe&#769;s",
+	})
+}
+
+func TestCheckMarkdown(t *testing.T) {
+	s := newMarkupSpell(t)
+
+	source := "# The quikc brown fox\n\n" +
+		"Jumps **ovr** the `lazzy` dog, see [link text](https://exampel.com \"tilte\").\n" +
+		"An ![image](img.png) and [text][refrence] and <https://exampel.com> and <b>bold</b>.\n" +
+		"Escaped \\*brwn\\* and caf&eacute;.\n\n" +
+		"```go\nfmt.Prntln(\"x\")\n```\n\n" +
+		"    indnted code\n\n" +
+		"[refrence]: https://exampel.com\n" +
+		"The dgo\n"
+
+	misspellings, err := s.CheckMarkdown(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkMisspellings(t, source, misspellings, map[string]string{
+		"quikc": "quikc",
+		"ovr":   "ovr",
+		"brwn":  "brwn",
+		"dgo":   "dgo",
+	})
+}
+
+func TestCheckMarkdown_inline(t *testing.T) {
+	s := newMarkupSpell(t)
+
+	// Delimiters within words don't split them, and code spans may cross
+	// line breaks
+	source := "The snake_cse and un*frig*ing *quick* _brwn_ fox.\n" +
+		"See `lazzy\ncodde` and * the dgo.\n"
+
+	misspellings, err := s.CheckMarkdown(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checkMisspellings(t, source, misspellings, map[string]string{
+		"snake_cse": "snake_cse",
+		"unfriging": "un*frig*ing",
+		"brwn":      "brwn",
+		"dgo":       "dgo",
+	})
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// backoffFactor is the penalty applied each time a lower order n-gram is used
+// to score a word, as in stupid backoff.
+const backoffFactor = 0.4
+
+// ngramSeparator joins the words of an n-gram to form its key.
+const ngramSeparator = " "
+
+// ngramModel holds bigram and trigram counts for each dictionary.
+type ngramModel struct {
+	sync.RWMutex
+	dictionaries map[string]*ngrams
+}
+
+// ngrams holds the count of each n-gram in a dictionary, along with the count
+// of each n-gram's context, i.e. all but its last word.
+type ngrams struct {
+	counts   map[string]uint64
+	contexts map[string]uint64
+}
+
+func newNGramModel() *ngramModel {
+	return &ngramModel{
+		dictionaries: make(map[string]*ngrams),
+	}
+}
+
+// add increases the count of an n-gram in a given dictionary.
+func (m *ngramModel) add(dict string, words []string, count uint64) {
+	m.Lock()
+	defer m.Unlock()
+
+	n, exists := m.dictionaries[dict]
+	if !exists {
+		n = &ngrams{
+			counts:   make(map[string]uint64),
+			contexts: make(map[string]uint64),
+		}
+		m.dictionaries[dict] = n
+	}
+
+	n.counts[strings.Join(words, ngramSeparator)] += count
+	n.contexts[strings.Join(words[:len(words)-1], ngramSeparator)] += count
+}
+
+// counts returns the count of an n-gram in a given dictionary and the count of
+// its context.
+func (m *ngramModel) counts(dict string, words []string) (uint64, uint64) {
+	m.RLock()
+	defer m.RUnlock()
+
+	n, exists := m.dictionaries[dict]
+	if !exists {
+		return 0, 0
+	}
+
+	return n.counts[strings.Join(words, ngramSeparator)],
+		n.contexts[strings.Join(words[:len(words)-1], ngramSeparator)]
+}
+
+// snapshot returns a copy of the n-gram counts for each dictionary.
+func (m *ngramModel) snapshot() map[string]map[string]uint64 {
+	m.RLock()
+	defer m.RUnlock()
+
+	snapshot := make(map[string]map[string]uint64, len(m.dictionaries))
+
+	for dict, n := range m.dictionaries {
+		counts := make(map[string]uint64, len(n.counts))
+		for key, count := range n.counts {
+			counts[key] = count
+		}
+
+		snapshot[dict] = counts
+	}
+
+	return snapshot
+}
+
+// AddNGram increases the count of an n-gram, given as a bigram or trigram of
+// words, in the dictionary. N-grams are used to score words by the words
+// around them, such as by LookupInContext.
+func (s *Spell) AddNGram(words []string, count uint64, opts ...DictionaryOption) error {
+	dictOpts := s.defaultDictOptions()
+
+	for _, opt := range opts {
+		if err := opt(dictOpts); err != nil {
+			return err
+		}
+	}
+
+	if len(words) < 2 || len(words) > 3 {
+		return errors.New("n-gram must be a bigram or trigram")
+	}
+
+	for _, word := range words {
+		if word == "" || strings.Contains(word, ngramSeparator) {
+			return errors.New("n-gram words must be non-empty and contain no spaces")
+		}
+	}
+
+	s.ngrams.add(dictOpts.name, words, count)
+
+	return nil
+}
+
+// ReadNGrams reads n-gram counts from r and adds them to the dictionary. Each
+// line holds a bigram or trigram of whitespace separated words followed by its
+// count, e.g. "of the 2766332391". Empty lines are ignored.
+func (s *Spell) ReadNGrams(r io.Reader, opts ...DictionaryOption) error {
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		count, err := strconv.ParseUint(fields[len(fields)-1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid count: %w", line, err)
+		}
+
+		if err := s.AddNGram(fields[:len(fields)-1], count, opts...); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// contextLogProb returns the log10 stupid backoff score of word following the
+// words in context, of which at most the last two are used. Without matching
+// n-grams, the score backs off to the word's frequency in the dictionary.
+func (s *Spell) contextLogProb(dict string, context []string, word string) float64 {
+	if len(context) > 2 {
+		context = context[len(context)-2:]
+	}
+
+	penalty := 1.0
+
+	for ; len(context) > 0; context = context[1:] {
+		if context[0] == "" {
+			continue
+		}
+
+		count, contextCount := s.ngrams.counts(dict, append(append([]string{}, context...), word))
+		if count > 0 && contextCount > 0 {
+			return math.Log10(penalty * float64(count) / float64(contextCount))
+		}
+
+		penalty *= backoffFactor
+	}
+
+	entry, _ := s.library.load(dict, word)
+
+	return math.Log10(penalty) + s.library.total(dict).logPrior(entry.Frequency)
+}
+
+// LookupInContext performs a Lookup for word, ranking its suggestions by how
+// likely they are to appear between the words prev and next, either of which
+// may be empty. Each suggestion's Score combines the error model with bigram
+// and trigram probabilities from the dictionary, and the word itself is
+// included as a suggestion if it's in the dictionary. Suggestions are ordered
+// by their Score, and only then limited by MaxResults.
+//
+// Accepts zero or more LookupOption that can be used to configure how lookup
+// occurs.
+func (s *Spell) LookupInContext(prev, word, next string, opts ...LookupOption) (SuggestionList, error) {
+	lookupParams := s.defaultLookupParams()
+
+	for _, opt := range opts {
+		if err := opt(lookupParams); err != nil {
+			return nil, err
+		}
+	}
+
+	// Every candidate is needed to rank them by their context, so they're
+	// only reduced once ranked
+	level, maxResults := lookupParams.suggestionLevel, lookupParams.maxResults
+	lookupParams.suggestionLevel, lookupParams.maxResults = LevelAll, 0
+
+	var (
+		results SuggestionList
+		err     error
+	)
+
+	if len(lookupParams.dictionaries) > 0 {
+		results, err = s.lookupDictionaries(context.Background(), word, lookupParams)
+	} else {
+		results, err = s.lookupDictionary(context.Background(), word, lookupParams.dictOpts.name, lookupParams)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	inputRunes := []rune(word)
+	evidence := 0.0
+
+	for i := range results {
+		sugg := &results[i]
+		ops := editOperations(inputRunes, []rune(sugg.Word))
+
+		sugg.Score = lookupParams.errorModel.LogProbability(ops) +
+			s.contextLogProb(sugg.Dictionary, []string{prev}, sugg.Word)
+
+		if next != "" {
+			sugg.Score += s.contextLogProb(sugg.Dictionary, []string{prev, sugg.Word}, next)
+		}
+
+		evidence += math.Pow(10, sugg.Score)
+	}
+
+	for i := range results {
+		results[i].Probability = math.Pow(10, results[i].Score) / evidence
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return lessScore(results[i], results[j])
+	})
+
+	// Reduce the suggestions to the requested level
+	switch level {
+	case LevelBest:
+		results = results[:min(len(results), 1)]
+	case LevelClosest:
+		closestDist := results.closest()
+		closest := results[:0]
+
+		for _, sugg := range results {
+			if sugg.Distance == closestDist {
+				closest = append(closest, sugg)
+			}
+		}
+
+		results = closest
+	}
+
+	if maxResults > 0 {
+		results = results[:min(len(results), maxResults)]
+	}
+
+	return results, nil
+}
+
+// closest returns the smallest distance of any suggestion in the list.
+func (s SuggestionList) closest() int {
+	closest := math.MaxInt
+
+	for _, sugg := range s {
+		closest = min(closest, sugg.Distance)
+	}
+
+	return closest
+}
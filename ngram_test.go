@@ -0,0 +1,164 @@
+package spell_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func newWithNGrams() (*spell.Spell, error) {
+	s, err := newWithWords(map[string]uint64{"from": 1000, "form": 100, "him": 500})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.AddNGram([]string{"letter", "from"}, 20); err != nil {
+		return nil, err
+	}
+	if err := s.AddNGram([]string{"from", "him"}, 10); err != nil {
+		return nil, err
+	}
+	if err := s.AddNGram([]string{"fill", "form"}, 5); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func TestLookupInContext(t *testing.T) {
+	s, err := newWithNGrams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without context, the word itself is the best suggestion
+	suggestions, err := s.LookupInContext("", "form", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[form]" {
+		t.Fatalf("expected [form], got %s", got)
+	}
+
+	// The context makes a different word more likely
+	suggestions, err = s.LookupInContext("letter", "form", "him")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[from]" {
+		t.Fatalf("expected [from], got %s", got)
+	}
+
+	suggestions, err = s.LookupInContext("fill", "fomr", "",
+		spell.SuggestionLevel(spell.LevelAll))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[form, from]" {
+		t.Fatalf("expected [form, from], got %s", got)
+	}
+
+	// Suggestions are limited once they're ranked by their context
+	suggestions, err = s.LookupInContext("letter", "form", "him",
+		spell.SuggestionLevel(spell.LevelAll), spell.MaxResults(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[from]" {
+		t.Fatalf("expected [from], got %s", got)
+	}
+
+	if err := s.AddNGram([]string{"one"}, 1); err == nil {
+		t.Fatal("expected error adding a unigram")
+	}
+}
+
+func TestSaveLoad_ngrams(t *testing.T) {
+	s1, err := newWithNGrams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s1.AddEntry(spell.Entry{Word: "française"},
+		spell.DictionaryName("french")); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Remove("./test_ngrams.dump")
+	if err := s1.Save("./test_ngrams.dump"); err != nil {
+		t.Fatal(err)
+	}
+	s2, err := spell.Load("./test_ngrams.dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s2.LookupInContext("letter", "form", "him")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[from]" {
+		t.Fatalf("expected [from], got %s", got)
+	}
+
+	entry, err := s2.GetEntry("française", spell.DictionaryName("french"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry == nil {
+		t.Fatal("expected entry to be loaded into its dictionary")
+	}
+}
+
+func TestReadNGrams(t *testing.T) {
+	s := spell.New()
+	if err := s.ReadNGrams(strings.NewReader("of the 100\n\nout of the 20\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReadNGrams(strings.NewReader("of the many\n")); err == nil {
+		t.Fatal("expected error for invalid count")
+	}
+	if err := s.ReadNGrams(strings.NewReader("of 10\n")); err == nil {
+		t.Fatal("expected error for unigram")
+	}
+}
+
+func TestSegment_bigrams(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"the": 10000, "rapist": 50, "therapist": 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ReadNGrams(strings.NewReader("the doctor 100\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Scored independently, the split words are more likely
+	result, err := s.Segment("therapist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.String(); got != "the rapist" {
+		t.Fatalf("expected 'the rapist', got %s", got)
+	}
+
+	// Without a bigram for the pair, the unsplit word is more likely
+	result, err = s.Segment("therapist", spell.SegmentBigrams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.String(); got != "therapist" {
+		t.Fatalf("expected 'therapist', got %s", got)
+	}
+
+	// Without context, a word is scored on the same scale either way
+	unigram, err := s.Segment("rapist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bigram, err := s.Segment("rapist", spell.SegmentBigrams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unigram.Probability != bigram.Probability {
+		t.Fatalf("expected probability %f, got %f", unigram.Probability, bigram.Probability)
+	}
+}
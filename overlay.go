@@ -0,0 +1,278 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+)
+
+// OverlayDictionary is the dictionary of suggestions for words added to an
+// Overlay.
+const OverlayDictionary = "overlay"
+
+// Overlay wraps a Spell with the words added and ignored by a single user or
+// for a single document, such as through the "Add to dictionary" and "Ignore
+// all" actions of an editor, without changing the Spell itself. Many overlays
+// may share the same Spell.
+type Overlay struct {
+	spell    *Spell
+	personal *Spell
+
+	mu          sync.RWMutex
+	ignored     map[string]struct{}
+	occurrences map[occurrence]struct{}
+}
+
+// occurrence is a word at a byte offset within some text.
+type occurrence struct {
+	word  string
+	start int
+}
+
+// NewOverlay creates a new overlay for s, without any words added or ignored.
+func NewOverlay(s *Spell) *Overlay {
+	personal := New()
+	personal.MaxEditDistance = s.MaxEditDistance
+	personal.PrefixLength = s.PrefixLength
+
+	return &Overlay{
+		spell:       s,
+		personal:    personal,
+		ignored:     make(map[string]struct{}),
+		occurrences: make(map[occurrence]struct{}),
+	}
+}
+
+// Spell returns the Spell wrapped by the overlay.
+func (o *Overlay) Spell() *Spell {
+	return o.spell
+}
+
+// AddEntry adds an entry to the overlay, so that its word is known and may be
+// suggested. If the word already exists in the overlay its data will be
+// overwritten. Returns true if a new word was added, false otherwise.
+func (o *Overlay) AddEntry(de Entry) (bool, error) {
+	return o.personal.AddEntry(de)
+}
+
+// GetEntry returns the Entry for a word added to the overlay. If the word
+// hasn't been added, nil will be returned.
+func (o *Overlay) GetEntry(word string) (*Entry, error) {
+	return o.personal.GetEntry(word)
+}
+
+// RemoveEntry removes an entry from the overlay. Returns true if the entry was
+// removed, false otherwise.
+func (o *Overlay) RemoveEntry(word string) (bool, error) {
+	return o.personal.RemoveEntry(word)
+}
+
+// IgnoreAll ignores every occurrence of word, so that it's treated as known
+// without being suggested for other words.
+func (o *Overlay) IgnoreAll(word string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.ignored[word] = struct{}{}
+}
+
+// IgnoreOnce ignores the occurrence of word at the byte offset start when
+// checking text with CheckOverlay. Ignored occurrences aren't persisted by
+// Save.
+func (o *Overlay) IgnoreOnce(word string, start int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.occurrences[occurrence{word: word, start: start}] = struct{}{}
+}
+
+// Unignore stops ignoring word, including any occurrences of it ignored once.
+func (o *Overlay) Unignore(word string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	delete(o.ignored, word)
+
+	for occ := range o.occurrences {
+		if occ.word == word {
+			delete(o.occurrences, occ)
+		}
+	}
+}
+
+// Ignored reports whether every occurrence of word is ignored.
+func (o *Overlay) Ignored(word string) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	_, ignored := o.ignored[word]
+
+	return ignored
+}
+
+// ignoredOnce reports whether the occurrence of word at start is ignored.
+func (o *Overlay) ignoredOnce(word string, start int) bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	_, ignored := o.occurrences[occurrence{word: word, start: start}]
+
+	return ignored
+}
+
+// Lookup is like Spell.Lookup, but also suggests the words added to the
+// overlay, from the dictionary OverlayDictionary. An ignored word is returned
+// as the only suggestion for itself, with a distance of 0.
+func (o *Overlay) Lookup(input string, opts ...LookupOption) (SuggestionList, error) {
+	return o.LookupContext(context.Background(), input, opts...)
+}
+
+// LookupContext is like Lookup but stops once ctx is done, in the same way as
+// Spell.LookupContext.
+func (o *Overlay) LookupContext(ctx context.Context, input string, opts ...LookupOption) (SuggestionList, error) {
+	lookupParams := o.spell.defaultLookupParams()
+
+	for _, opt := range opts {
+		if err := opt(lookupParams); err != nil {
+			return nil, err
+		}
+	}
+
+	if o.Ignored(input) {
+		return SuggestionList{{
+			Dictionary: OverlayDictionary,
+			Entry:      Entry{Word: input},
+		}}, nil
+	}
+
+	results, err := o.spell.LookupContext(ctx, input, opts...)
+	if err != nil {
+		return results, err
+	}
+
+	personalOpts := append(opts[:len(opts):len(opts)], o.personalDictionary())
+
+	personal, err := o.personal.LookupContext(ctx, input, personalOpts...)
+	if len(personal) == 0 {
+		return results, err
+	}
+
+	words := make(map[string]bool, len(results))
+	for _, sugg := range results {
+		words[sugg.Word] = true
+	}
+
+	for _, sugg := range personal {
+		if !words[sugg.Word] {
+			sugg.Dictionary = OverlayDictionary
+			results = append(results, sugg)
+		}
+	}
+
+	// Reduce the combined suggestions to the requested level
+	less := lookupParams.less()
+	sort.SliceStable(results, func(i, j int) bool {
+		return less(results[i], results[j])
+	})
+
+	switch lookupParams.suggestionLevel {
+	case LevelBest:
+		results = results[:1]
+	case LevelClosest:
+		closest := results.closest()
+		kept := results[:0]
+
+		for _, sugg := range results {
+			if sugg.Distance == closest {
+				kept = append(kept, sugg)
+			}
+		}
+
+		results = kept
+	}
+
+	if lookupParams.maxResults > 0 && len(results) > lookupParams.maxResults {
+		results = results[:lookupParams.maxResults]
+	}
+
+	lookupParams.sortFunc(results)
+
+	return results, err
+}
+
+// personalDictionary returns a LookupOption which looks up the words added to
+// the overlay, which are in the default dictionary of its own Spell.
+func (o *Overlay) personalDictionary() LookupOption {
+	return func(lp *lookupParams) error {
+		lp.dictionaries = nil
+		lp.dictOpts = o.personal.defaultDictOptions()
+
+		return nil
+	}
+}
+
+// overlayFile is the representation of an overlay on disk.
+type overlayFile struct {
+	Words   []Entry  `json:"words"`
+	Ignored []string `json:"ignored"`
+}
+
+// Save the words added to and ignored by the overlay to disk at filename, as
+// JSON. The Spell wrapped by the overlay isn't saved.
+func (o *Overlay) Save(filename string) error {
+	file := overlayFile{
+		Words: o.personal.library.entries(o.personal.defaultDictOptions().name),
+	}
+
+	o.mu.RLock()
+	for word := range o.ignored {
+		file.Ignored = append(file.Ignored, word)
+	}
+	o.mu.RUnlock()
+
+	sort.Slice(file.Words, func(i, j int) bool {
+		return file.Words[i].Word < file.Words[j].Word
+	})
+	sort.Strings(file.Ignored)
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, 0o644)
+}
+
+// LoadOverlay loads an overlay saved to disk at filename, wrapping s. Returns
+// an error if there's a problem reading the file.
+func LoadOverlay(s *Spell, filename string) (*Overlay, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var file overlayFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	o := NewOverlay(s)
+
+	for _, entry := range file.Words {
+		if _, err := o.AddEntry(entry); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, word := range file.Ignored {
+		o.IgnoreAll(word)
+	}
+
+	return o, nil
+}
@@ -0,0 +1,125 @@
+package spell_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func TestOverlay(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"hello": 100, "world": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := spell.NewOverlay(s)
+
+	if _, err := o.AddEntry(spell.Entry{Word: "gopher", Frequency: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Words added to the overlay are suggested
+	suggestions, err := o.Lookup("gophr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Word != "gopher" ||
+		suggestions[0].Dictionary != spell.OverlayDictionary {
+		t.Fatalf("expected gopher from the overlay, got %v", suggestions)
+	}
+
+	suggestions, err = o.Lookup("wrld", spell.SuggestionLevel(spell.LevelAll))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suggestions.String() != "[world]" {
+		t.Fatalf("expected [world], got %v", suggestions)
+	}
+
+	// The wrapped Spell is unchanged
+	if suggestions, _ := s.Lookup("gopher"); len(suggestions) != 0 {
+		t.Fatalf("expected no suggestions from the spell, got %v", suggestions)
+	}
+
+	o.IgnoreAll("teh")
+
+	text := "helo gopher teh wrld helo"
+
+	misspellings, err := s.CheckText(text, spell.CheckOverlay(o))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"helo", "wrld", "helo"}
+	if got := misspeltWords(misspellings); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	o.IgnoreOnce("helo", misspellings[2].Start)
+
+	misspellings, err = s.CheckText(text, spell.CheckOverlay(o))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{"helo", "wrld"}
+	if got := misspeltWords(misspellings); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+
+	o.Unignore("teh")
+	o.Unignore("helo")
+
+	if o.Ignored("teh") {
+		t.Fatal("expected teh not to be ignored")
+	}
+
+	misspellings, err = s.CheckText(text, spell.CheckOverlay(o))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{"helo", "teh", "wrld", "helo"}
+	if got := misspeltWords(misspellings); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestOverlay_saveLoad(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"hello": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := spell.NewOverlay(s)
+	if _, err := o.AddEntry(spell.Entry{Word: "gopher", Frequency: 3}); err != nil {
+		t.Fatal(err)
+	}
+	o.IgnoreAll("teh")
+
+	filename := filepath.Join(t.TempDir(), "overlay.json")
+	if err := o.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := spell.LoadOverlay(s, filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry, err := loaded.GetEntry("gopher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry == nil || entry.Frequency != 3 {
+		t.Fatalf("expected gopher with frequency 3, got %v", entry)
+	}
+	if !loaded.Ignored("teh") {
+		t.Fatal("expected teh to be ignored")
+	}
+	if loaded.Spell() != s {
+		t.Fatal("expected the loaded overlay to wrap the spell")
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+const defaultRealWordThreshold = 10
+
+// RealWordError is a word which is in the dictionary, but is likely to be
+// wrong given the words around it.
+type RealWordError struct {
+	// The index of the word in the words checked
+	Index int
+	Word  string
+
+	// The alternatives which are more likely in context, most likely first.
+	// The Score of each is its log10 probability in context
+	Suggestions SuggestionList
+}
+
+type realWordParams struct {
+	confusionSets map[string][]string
+	editDistance  uint32
+	lookupOptions []LookupOption
+	threshold     float64
+}
+
+func (s *Spell) defaultRealWordParams() *realWordParams {
+	return &realWordParams{
+		confusionSets: make(map[string][]string),
+		editDistance:  1,
+		threshold:     defaultRealWordThreshold,
+	}
+}
+
+// RealWordOption is a function that controls how real-word errors are found.
+// An error will be returned if the RealWordOption is invalid.
+type RealWordOption func(*realWordParams) error
+
+// ConfusionSet defines words which are commonly confused with each other, such
+// as "peace" and "piece". Each word in the set is considered as an
+// alternative to the others. May be given multiple times.
+func ConfusionSet(words ...string) RealWordOption {
+	return func(rp *realWordParams) error {
+		if len(words) < 2 {
+			return errors.New("confusion set must contain at least two words")
+		}
+
+		for _, word := range words {
+			for _, alternative := range words {
+				if alternative != word {
+					rp.confusionSets[word] = append(rp.confusionSets[word], alternative)
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+// RealWordEditDistance sets the edit distance within which dictionary words
+// are considered as alternatives, in addition to any confusion sets. By
+// default, words within an edit distance of 1 are considered. An edit distance
+// of 0 restricts the alternatives to those from confusion sets.
+func RealWordEditDistance(dist uint32) RealWordOption {
+	return func(rp *realWordParams) error {
+		rp.editDistance = dist
+
+		return nil
+	}
+}
+
+// RealWordLookupOpts allows the Lookup() options used to find alternatives to
+// be configured, such as the dictionary that should be used.
+func RealWordLookupOpts(opts ...LookupOption) RealWordOption {
+	return func(rp *realWordParams) error {
+		rp.lookupOptions = opts
+
+		return nil
+	}
+}
+
+// RealWordThreshold sets how many times more likely in context an alternative
+// must be than the word for the word to be flagged. Defaults to 10.
+func RealWordThreshold(ratio float64) RealWordOption {
+	return func(rp *realWordParams) error {
+		if ratio < 1 {
+			return errors.New("real word threshold must be at least 1")
+		}
+
+		rp.threshold = ratio
+
+		return nil
+	}
+}
+
+// CheckRealWords takes a sequence of words and returns those which are in the
+// dictionary, but have an alternative that is much more likely given the words
+// around them. Alternatives come from confusion sets and from dictionary words
+// within an edit distance, and are compared using the dictionary's n-grams.
+//
+// Accepts zero or more RealWordOption that can be used to configure how
+// checking occurs.
+func (s *Spell) CheckRealWords(words []string, opts ...RealWordOption) ([]RealWordError, error) {
+	realWordParams := s.defaultRealWordParams()
+
+	for _, opt := range opts {
+		if err := opt(realWordParams); err != nil {
+			return nil, err
+		}
+	}
+
+	lookupParams := s.defaultLookupParams()
+
+	for _, opt := range realWordParams.lookupOptions {
+		if err := opt(lookupParams); err != nil {
+			return nil, err
+		}
+	}
+
+	dict := lookupParams.dictOpts.name
+	threshold := math.Log10(realWordParams.threshold)
+
+	var realWordErrors []RealWordError
+
+	for i, word := range words {
+		if _, exists := s.library.load(dict, word); !exists {
+			continue
+		}
+
+		alternatives, err := s.realWordAlternatives(word, dict, realWordParams)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(alternatives) == 0 {
+			continue
+		}
+
+		wordScore := s.windowLogProb(dict, words, i, word)
+
+		var suggestions SuggestionList
+
+		for _, sugg := range alternatives {
+			sugg.Score = s.windowLogProb(dict, words, i, sugg.Word)
+			if sugg.Score-wordScore >= threshold {
+				suggestions = append(suggestions, sugg)
+			}
+		}
+
+		if len(suggestions) == 0 {
+			continue
+		}
+
+		sort.SliceStable(suggestions, func(i, j int) bool {
+			return lessScore(suggestions[i], suggestions[j])
+		})
+
+		realWordErrors = append(realWordErrors, RealWordError{
+			Index:       i,
+			Word:        word,
+			Suggestions: suggestions,
+		})
+	}
+
+	return realWordErrors, nil
+}
+
+// realWordAlternatives returns the dictionary words which could have been
+// intended instead of word.
+func (s *Spell) realWordAlternatives(word, dict string, rp *realWordParams) (SuggestionList, error) {
+	var alternatives SuggestionList
+
+	seen := map[string]struct{}{word: {}}
+
+	for _, alternative := range rp.confusionSets[word] {
+		if _, exists := s.library.load(dict, alternative); exists && addKey(seen, alternative) {
+			sugg := s.newDictSuggestion(alternative, len(editOperations([]rune(word), []rune(alternative))), dict)
+			alternatives = append(alternatives, sugg)
+		}
+	}
+
+	if rp.editDistance == 0 {
+		return alternatives, nil
+	}
+
+	opts := append(append([]LookupOption{}, rp.lookupOptions...),
+		SuggestionLevel(LevelAll), EditDistance(rp.editDistance))
+
+	suggestions, err := s.Lookup(word, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sugg := range suggestions {
+		if addKey(seen, sugg.Word) {
+			alternatives = append(alternatives, sugg)
+		}
+	}
+
+	return alternatives, nil
+}
+
+// windowLogProb returns the log10 probability of word appearing at index i of
+// words, taking into account the n-grams it forms with up to two words either
+// side of it.
+func (s *Spell) windowLogProb(dict string, words []string, i int, word string) float64 {
+	at := func(j int) string {
+		if j < 0 || j >= len(words) {
+			return ""
+		}
+
+		if j == i {
+			return word
+		}
+
+		return words[j]
+	}
+
+	logProb := s.contextLogProb(dict, []string{at(i - 2), at(i - 1)}, word)
+
+	for j := i + 1; j <= i+2 && j < len(words); j++ {
+		logProb += s.contextLogProb(dict, []string{at(j - 2), at(j - 1)}, words[j])
+	}
+
+	return logProb
+}
@@ -0,0 +1,60 @@
+package spell_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func TestCheckRealWords(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"a": 1000, "peace": 50, "piece": 50, "of": 1000, "cake": 20, "war": 30,
+		"and": 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ngram := range []string{"a piece", "piece of", "piece of cake", "war and peace"} {
+		if err := s.AddNGram(strings.Fields(ngram), 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	words := strings.Fields("a peace of cake")
+
+	// Peace and piece are too far apart to be found by edit distance
+	realWordErrors, err := s.CheckRealWords(words)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(realWordErrors) != 0 {
+		t.Fatalf("expected no errors, got %+v", realWordErrors)
+	}
+
+	realWordErrors, err = s.CheckRealWords(words, spell.ConfusionSet("peace", "piece"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(realWordErrors) != 1 {
+		t.Fatalf("expected one error, got %+v", realWordErrors)
+	}
+	if e := realWordErrors[0]; e.Index != 1 || e.Word != "peace" ||
+		e.Suggestions.String() != "[piece]" {
+		t.Fatalf("expected peace to be flagged with piece, got %+v", e)
+	}
+
+	// Words used correctly aren't flagged
+	realWordErrors, err = s.CheckRealWords(strings.Fields("war and peace"),
+		spell.ConfusionSet("peace", "piece"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(realWordErrors) != 0 {
+		t.Fatalf("expected no errors, got %+v", realWordErrors)
+	}
+
+	if _, err := s.CheckRealWords(words, spell.ConfusionSet("peace")); err == nil {
+		t.Fatal("expected error for confusion set with one word")
+	}
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+type editType int
+
+// Edit Types describing how an input is transformed into a suggestion.
+const (
+	// EditInsert inserts a rune into the input.
+	EditInsert editType = iota
+
+	// EditDelete deletes a rune from the input.
+	EditDelete
+
+	// EditSubstitute replaces a rune in the input with another.
+	EditSubstitute
+
+	// EditTranspose swaps two adjacent runes in the input.
+	EditTranspose
+)
+
+// String returns the name of the edit type.
+func (t editType) String() string {
+	switch t {
+	case EditInsert:
+		return "insert"
+	case EditDelete:
+		return "delete"
+	case EditSubstitute:
+		return "substitute"
+	case EditTranspose:
+		return "transpose"
+	}
+
+	return "unknown"
+}
+
+// EditOperation is a single edit which forms part of the transformation of an
+// input into a suggestion.
+type EditOperation struct {
+	Type editType
+
+	// The rune offset in the input at which the edit occurs
+	Position int
+
+	// The runes removed from the input, empty for an insert
+	From string
+
+	// The runes added to the input, empty for a delete
+	To string
+}
+
+// ErrorModel estimates how likely an input is to have been typed when a word
+// was intended, given the edit operations which transform the input into the
+// word.
+type ErrorModel interface {
+	// LogProbability returns log10 P(input|word).
+	LogProbability(ops []EditOperation) float64
+}
+
+// EditProbabilities is an ErrorModel which assigns each type of edit a fixed
+// probability. The probability of an input is the product of the probability
+// of each of its edits, or NoEdit if it has none.
+type EditProbabilities struct {
+	NoEdit     float64
+	Insert     float64
+	Delete     float64
+	Substitute float64
+	Transpose  float64
+}
+
+// defaultErrorModel is used to score suggestions unless another ErrorModel is
+// given. It's held as an ErrorModel so that each lookup doesn't allocate it.
+var defaultErrorModel ErrorModel = EditProbabilities{
+	NoEdit:     0.95,
+	Insert:     0.01,
+	Delete:     0.01,
+	Substitute: 0.01,
+	Transpose:  0.01,
+}
+
+// LogProbability returns log10 P(input|word) for the given edits.
+func (p EditProbabilities) LogProbability(ops []EditOperation) float64 {
+	if len(ops) == 0 {
+		return math.Log10(p.NoEdit)
+	}
+
+	logProb := 0.0
+
+	for _, op := range ops {
+		switch op.Type {
+		case EditInsert:
+			logProb += math.Log10(p.Insert)
+		case EditDelete:
+			logProb += math.Log10(p.Delete)
+		case EditSubstitute:
+			logProb += math.Log10(p.Substitute)
+		case EditTranspose:
+			logProb += math.Log10(p.Transpose)
+		}
+	}
+
+	return logProb
+}
+
+// ChannelModel sets the ErrorModel used to score suggestions. By default, each
+// edit has a probability of 0.01 and an exact match has a probability of 0.95.
+func ChannelModel(m ErrorModel) LookupOption {
+	return func(lp *lookupParams) error {
+		if m == nil {
+			return errors.New("error model must not be nil")
+		}
+
+		lp.errorModel = m
+
+		return nil
+	}
+}
+
+// Probabilities sets the Score and Probability of each suggestion returned by
+// the Lookup, without changing how they're ranked. They're also set by
+// RankByScore and Explain.
+func Probabilities() LookupOption {
+	return func(lp *lookupParams) error {
+		lp.scores = true
+
+		return nil
+	}
+}
+
+// RankByScore ranks suggestions by their Score rather than by their edit
+// distance and frequency. At LevelBest, this allows a more likely suggestion
+// to be chosen over a closer one.
+func RankByScore() LookupOption {
+	return func(lp *lookupParams) error {
+		lp.rankByScore = true
+		lp.sortFunc = func(results SuggestionList) {
+			sort.SliceStable(results, func(i, j int) bool {
+				return lessScore(results[i], results[j])
+			})
+		}
+
+		return nil
+	}
+}
+
+// lessScore orders suggestions by their score, then their edit distance.
+func lessScore(s1, s2 Suggestion) bool {
+	if s1.Score != s2.Score {
+		return s1.Score > s2.Score
+	}
+
+	return s1.Distance < s2.Distance
+}
+
+// score returns the log10 probability of a word being intended when an input
+// was typed, given the edits between them. It combines the error model with a
+// prior from the word's frequency relative to the total for its dictionary.
+func score(model ErrorModel, total dictionaryTotal, ops []EditOperation, freq uint64) float64 {
+	return model.LogProbability(ops) + total.logPrior(freq)
+}
+
+// logPrior returns the log10 probability of a word with the given frequency
+// occurring in a dictionary with this total.
+func (t dictionaryTotal) logPrior(freq uint64) float64 {
+	// Add-one smoothing avoids a zero prior for words without a frequency
+	return math.Log10(float64(freq+1) / math.Max(float64(t.frequency+t.words), 1))
+}
+
+// editOperations returns the edits which transform input into word, as found
+// by an optimal string alignment.
+func editOperations(input, word []rune) []EditOperation {
+	n, m := len(input), len(word)
+
+	d := make([][]int, n+1)
+	for i := range d {
+		d[i] = make([]int, m+1)
+		d[i][0] = i
+	}
+
+	for j := 0; j <= m; j++ {
+		d[0][j] = j
+	}
+
+	transposed := func(i, j int) bool {
+		return i > 1 && j > 1 && input[i-1] != input[i-2] &&
+			input[i-1] == word[j-2] && input[i-2] == word[j-1]
+	}
+
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			cost := 1
+			if input[i-1] == word[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(min(d[i-1][j]+1, d[i][j-1]+1), d[i-1][j-1]+cost)
+
+			if transposed(i, j) {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	// Walk back through the matrix to recover the edits
+	var ops []EditOperation
+
+	for i, j := n, m; i > 0 || j > 0; {
+		switch {
+		case transposed(i, j) && d[i][j] == d[i-2][j-2]+1:
+			ops = append(ops, EditOperation{
+				Type:     EditTranspose,
+				Position: i - 2,
+				From:     string(input[i-2 : i]),
+				To:       string(word[j-2 : j]),
+			})
+			i, j = i-2, j-2
+		case i > 0 && j > 0 && input[i-1] == word[j-1] && d[i][j] == d[i-1][j-1]:
+			i, j = i-1, j-1
+		case i > 0 && j > 0 && d[i][j] == d[i-1][j-1]+1:
+			ops = append(ops, EditOperation{
+				Type:     EditSubstitute,
+				Position: i - 1,
+				From:     string(input[i-1]),
+				To:       string(word[j-1]),
+			})
+			i, j = i-1, j-1
+		case i > 0 && d[i][j] == d[i-1][j]+1:
+			ops = append(ops, EditOperation{
+				Type:     EditDelete,
+				Position: i - 1,
+				From:     string(input[i-1]),
+			})
+			i--
+		default:
+			ops = append(ops, EditOperation{
+				Type:     EditInsert,
+				Position: i,
+				To:       string(word[j-1]),
+			})
+			j--
+		}
+	}
+
+	// Return the edits in the order they occur in the input
+	for l, r := 0, len(ops)-1; l < r; l, r = l+1, r-1 {
+		ops[l], ops[r] = ops[r], ops[l]
+	}
+
+	return ops
+}
@@ -0,0 +1,134 @@
+package spell_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func TestLookup_score(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"from": 100000, "form": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// By default the closest suggestion is the best
+	suggestions, err := s.Lookup("fomr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Word != "form" {
+		t.Fatalf("expected form, got %v", suggestions)
+	}
+
+	// Ranking by score favours the far more frequent word
+	suggestions, err = s.Lookup("fomr", spell.RankByScore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Word != "from" {
+		t.Fatalf("expected from, got %v", suggestions)
+	}
+
+	suggestions, err = s.Lookup("fomr", spell.RankByScore(),
+		spell.SuggestionLevel(spell.LevelAll))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[from, form]" {
+		t.Fatalf("expected [from, form], got %s", got)
+	}
+
+	total := 0.0
+	for _, sugg := range suggestions {
+		total += sugg.Probability
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Fatalf("expected probabilities to sum to 1, got %f", total)
+	}
+	if suggestions[0].Probability < 0.9 {
+		t.Fatalf("expected from to be very likely, got %f", suggestions[0].Probability)
+	}
+}
+
+func TestLookup_probabilities(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"from": 100000, "form": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Suggestions aren't scored unless asked for
+	suggestions, err := s.Lookup("fomr", spell.SuggestionLevel(spell.LevelAll))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sugg := range suggestions {
+		if sugg.Score != 0 || sugg.Probability != 0 {
+			t.Fatalf("expected %s not to be scored, got %+v", sugg.Word, sugg)
+		}
+	}
+
+	// Probabilities scores suggestions without changing their order
+	suggestions, err = s.Lookup("fomr", spell.SuggestionLevel(spell.LevelAll), spell.Probabilities())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[form, from]" {
+		t.Fatalf("expected [form, from], got %s", got)
+	}
+	if suggestions[1].Probability < 0.9 || suggestions[0].Score >= suggestions[1].Score {
+		t.Fatalf("expected from to be the most likely, got %+v", suggestions)
+	}
+}
+
+func TestLookup_channelModel(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"from": 100000, "form": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Making transpositions far more likely than other edits favours form
+	suggestions, err := s.Lookup("fomr", spell.RankByScore(),
+		spell.ChannelModel(spell.EditProbabilities{
+			NoEdit:     0.9,
+			Insert:     0.0001,
+			Delete:     0.0001,
+			Substitute: 0.0001,
+			Transpose:  0.5,
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Word != "form" {
+		t.Fatalf("expected form, got %v", suggestions)
+	}
+}
+
+func TestLookup_rankByScoreMaxResults(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"abce": 1, "abxy": 1000000000, "abcf": 5, "xbcd": 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ranked, err := s.Lookup("abcd", spell.SuggestionLevel(spell.LevelAll), spell.RankByScore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranked) != 4 || ranked[0].Word != "abxy" {
+		t.Fatalf("expected abxy to be ranked first, got %s", ranked)
+	}
+
+	// Limiting the results keeps the best of the ranking, even when further
+	// words score higher than closer ones
+	for n := 1; n <= len(ranked); n++ {
+		suggestions, err := s.Lookup("abcd", spell.SuggestionLevel(spell.LevelAll), spell.RankByScore(),
+			spell.MaxResults(n))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, expected := suggestions.String(), ranked[:n].String(); got != expected {
+			t.Errorf("max results %d: expected %s, got %s", n, expected, got)
+		}
+	}
+}
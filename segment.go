@@ -0,0 +1,848 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+type segmentParams struct {
+	bigrams       bool
+	ignoreRules   []IgnoreRule
+	lookupOptions []LookupOption
+	scorer        SegmentScorer
+}
+
+func (s *Spell) defaultSegmentParams() *segmentParams {
+	return &segmentParams{
+		lookupOptions: []LookupOption{
+			SuggestionLevel(LevelBest),
+		},
+		// Words containing digits are handled by the segmentation itself,
+		// so that digits within known words such as "mp3" can be split from
+		// the words around them
+		ignoreRules: []IgnoreRule{IgnoreURLs, IgnoreEmails, IgnoreHexHashes, IgnoreVersions},
+		scorer:      DefaultSegmentScorer{},
+	}
+}
+
+// SegmentOption is a function that controls how a Segment is performed. An
+// error will be returned if the SegmentOption is invalid.
+type SegmentOption func(*segmentParams) error
+
+// SegmentLookupOpts allows the Lookup() options for the current segmentation to
+// be configured.
+func SegmentLookupOpts(opt ...LookupOption) SegmentOption {
+	return func(sp *segmentParams) error {
+		sp.lookupOptions = opt
+
+		return nil
+	}
+}
+
+// SegmentBigrams scores each word of a segmentation by the probability of it
+// following the previous word, using the dictionary's bigrams and backing off
+// to the word's frequency when there are none. By default, each word is scored
+// independently by its frequency. Bigrams can be added with AddNGram or
+// ReadNGrams.
+func SegmentBigrams() SegmentOption {
+	return func(sp *segmentParams) error {
+		sp.bigrams = true
+
+		return nil
+	}
+}
+
+// SegmentIgnoreRules sets the rules which find parts of the input which are
+// passed through segmentation unchanged, in the same way as numbers,
+// punctuation, symbols and URLs always are. By default, the rules of the
+// default rule pack returned by DefaultIgnoreRules are used, other than
+// IgnoreNumbers, as digits within unknown words are already passed through.
+// IgnoreAcronyms may also be given, though it passes through any input which
+// is entirely upper case. If no rules are given, only numbers, punctuation,
+// symbols and URLs are passed through.
+func SegmentIgnoreRules(rules ...IgnoreRule) SegmentOption {
+	return func(sp *segmentParams) error {
+		for _, rule := range rules {
+			if rule == nil {
+				return errors.New("ignore rule must not be nil")
+			}
+		}
+
+		sp.ignoreRules = rules
+
+		return nil
+	}
+}
+
+// SegmentScoring sets the SegmentScorer used to score the words of each
+// segmentation and to rank segmentations. By default, DefaultSegmentScorer is
+// used.
+func SegmentScoring(scorer SegmentScorer) SegmentOption {
+	return func(sp *segmentParams) error {
+		if scorer == nil {
+			return errors.New("segment scorer must not be nil")
+		}
+
+		sp.scorer = scorer
+
+		return nil
+	}
+}
+
+// SegmentWord describes a part of the input being scored during segmentation.
+type SegmentWord struct {
+	// The part of the input, with any spaces removed
+	Input string
+
+	// The word before this part in the segmentation, empty at its start
+	Previous string
+
+	// The best suggestion for the part, or nil if the part is unknown
+	Suggestion *Suggestion
+
+	// The log10 probability of the suggestion, from its frequency plus one
+	// relative to the cumulative frequency, or from bigrams if SegmentBigrams
+	// is set
+	Probability float64
+
+	// The sum of the frequencies of the words in the dictionary, plus one for
+	// each word to smooth their probabilities
+	CumulativeFrequency float64
+}
+
+// SegmentScore is the score of a segmentation of the start of the input.
+type SegmentScore struct {
+	// The sum of the edit distances of each word from its part of the input,
+	// including any separators added between words
+	Distance int
+
+	// The log10 probability of the segmentation
+	Probability float64
+
+	// The length of the separator added before the last word, either 0 or 1
+	SeparatorLength int
+
+	// The number of separators added between words
+	Separators int
+}
+
+// SegmentScorer scores the words of a segmentation and decides how distance and
+// probability trade off when ranking segmentations.
+type SegmentScorer interface {
+	// KnownWord returns the log10 probability of a part with a suggestion.
+	KnownWord(w SegmentWord) float64
+
+	// UnknownWord returns the log10 probability of a part without a
+	// suggestion.
+	UnknownWord(w SegmentWord) float64
+
+	// Better reports whether candidate should be ranked above current, where
+	// both segment the same part of the input. It must be a strict weak
+	// ordering, so that the segmentations returned by SegmentN are ordered
+	// best first.
+	Better(candidate, current SegmentScore) bool
+}
+
+// DefaultSegmentScorer is the SegmentScorer used unless another is given. It
+// may be embedded by other scorers to override some of its behaviour.
+type DefaultSegmentScorer struct{}
+
+// KnownWord returns the probability of the word's suggestion.
+func (DefaultSegmentScorer) KnownWord(w SegmentWord) float64 {
+	return w.Probability
+}
+
+// UnknownWord returns a probability which decreases tenfold with each rune of
+// the part, so that long unknown parts are unlikely.
+func (DefaultSegmentScorer) UnknownWord(w SegmentWord) float64 {
+	return math.Log10(10.0 / (w.CumulativeFrequency *
+		math.Pow(10.0, float64(len([]rune(w.Input))))))
+}
+
+// Better ranks the candidate above current if it's closer to the input,
+// disregarding the separators added between words. Otherwise, the more likely
+// segmentation is ranked first, and then the one closer to the input.
+func (DefaultSegmentScorer) Better(candidate, current SegmentScore) bool {
+	if c, o := candidate.Distance-candidate.Separators, current.Distance-current.Separators; c != o {
+		return c < o
+	}
+
+	if candidate.Probability != current.Probability {
+		return candidate.Probability > current.Probability
+	}
+
+	return candidate.Distance < current.Distance
+}
+
+// Segment contains details about an individual segment.
+type Segment struct {
+	Input string
+	Entry *Entry
+	Word  string
+
+	// The byte offsets of the segment within the input
+	Start, End int
+
+	// Whether the segment is a number, punctuation, a symbol or a URL which
+	// was passed through unchanged
+	PassThrough bool
+}
+
+// SegmentResult holds the result of a call to Segment().
+type SegmentResult struct {
+	Distance int
+
+	// The log10 probability of the segmentation
+	Probability float64
+	Segments    []Segment
+
+	input string
+}
+
+// GetWords returns a string slice of words for the segments.
+func (s SegmentResult) GetWords() []string {
+	words := make([]string, 0, len(s.Segments))
+	for _, s := range s.Segments {
+		words = append(words, s.Word)
+	}
+
+	return words
+}
+
+// String returns a string representation of the SegmentList. The original
+// spacing between segments is kept, and words which were joined in the input
+// are separated by a single space.
+func (s SegmentResult) String() string {
+	var b strings.Builder
+
+	for i, seg := range s.Segments {
+		if i > 0 {
+			prev := s.Segments[i-1]
+
+			var space string
+			if prev.End <= seg.Start && seg.Start <= len(s.input) {
+				space = s.input[prev.End:seg.Start]
+			}
+
+			if space == "" && !prev.PassThrough && !seg.PassThrough {
+				space = " "
+			}
+
+			b.WriteString(space)
+		}
+
+		b.WriteString(seg.Word)
+	}
+
+	return b.String()
+}
+
+// Segment takes an input string which may have word concatenations, and
+// attempts to divide it into the most likely set of words by adding spaces at
+// the most appropriate positions.
+//
+// Accepts zero or more SegmentOption that can be used to configure how
+// segmentation occurs.
+func (s *Spell) Segment(input string, opts ...SegmentOption) (*SegmentResult, error) {
+	return s.SegmentContext(context.Background(), input, opts...)
+}
+
+// SegmentContext is like Segment but stops once ctx is done. In that case the
+// best segmentation of the input examined so far is returned, followed by the
+// unexamined remainder as a single unknown segment, along with an error
+// wrapping both ErrInterrupted and the context's error.
+func (s *Spell) SegmentContext(ctx context.Context, input string, opts ...SegmentOption) (*SegmentResult, error) {
+	results, err := s.segment(ctx, input, 1, opts...)
+	if len(results) == 0 {
+		return nil, err
+	}
+
+	return results[0], err
+}
+
+// SegmentN is like Segment but returns up to n alternative segmentations of the
+// input, best first.
+func (s *Spell) SegmentN(input string, n int, opts ...SegmentOption) ([]*SegmentResult, error) {
+	return s.SegmentNContext(context.Background(), input, n, opts...)
+}
+
+// SegmentNContext is like SegmentN but stops once ctx is done, in the same way
+// as SegmentContext.
+func (s *Spell) SegmentNContext(ctx context.Context, input string, n int, opts ...SegmentOption) ([]*SegmentResult, error) {
+	if n < 1 {
+		return nil, errors.New("number of segmentations must be greater than 0")
+	}
+
+	return s.segment(ctx, input, n, opts...)
+}
+
+// segmentPart is a part of the input within a composition, linked to the part
+// before it.
+type segmentPart struct {
+	prev        *segmentPart
+	input       string
+	word        string
+	start, end  int
+	passThrough bool
+}
+
+// composition is a segmentation of the start of the input.
+type composition struct {
+	last            *segmentPart
+	distanceSum     int
+	probability     float64
+	separatorLength int
+	separators      int
+
+	// Whether the input following the last part is whitespace
+	spaced bool
+}
+
+// needsSeparator reports whether a separator must be added between the
+// composition and the next word.
+func (c composition) needsSeparator() bool {
+	return c.last != nil && !c.last.passThrough && !c.spaced
+}
+
+// score returns the score of the composition.
+func (c composition) score() SegmentScore {
+	return SegmentScore{
+		Distance:        c.distanceSum,
+		Probability:     c.probability,
+		SeparatorLength: c.separatorLength,
+		Separators:      c.separators,
+	}
+}
+
+// better reports whether composition c should be ranked above other according
+// to scorer.
+func (c composition) better(other composition, scorer SegmentScorer) bool {
+	return scorer.Better(c.score(), other.score())
+}
+
+// sameWords reports whether two compositions, given by their last parts,
+// consist of the same words.
+func sameWords(a, b *segmentPart) bool {
+	for ; a != nil && b != nil; a, b = a.prev, b.prev {
+		if a == b {
+			return true
+		}
+
+		if a.word != b.word {
+			return false
+		}
+	}
+
+	return a == b
+}
+
+// insertComposition adds c to compositions, which is ordered best first,
+// retaining at most k compositions. Only the best composition of any sequence
+// of words is kept.
+func insertComposition(compositions []composition, c composition, scorer SegmentScorer, k int) []composition {
+	for i := range compositions {
+		if sameWords(compositions[i].last, c.last) {
+			if !c.better(compositions[i], scorer) {
+				return compositions
+			}
+
+			compositions = append(compositions[:i], compositions[i+1:]...)
+
+			break
+		}
+	}
+
+	for i := range compositions {
+		if c.better(compositions[i], scorer) {
+			compositions = append(compositions[:i+1], compositions[i:]...)
+			compositions[i] = c
+
+			return compositions[:min(len(compositions), k)]
+		}
+	}
+
+	if len(compositions) < k {
+		compositions = append(compositions, c)
+	}
+
+	return compositions
+}
+
+// passThroughs finds the parts of the input which are passed through
+// segmentation unchanged: the ignored parts, given by their rune offsets, as
+// well as URLs, numbers, punctuation and symbols such as emoji. Numbers within
+// a word are only passed through if no known word of up to longest runes
+// includes them, so that words such as "mp3" are kept together. The returned
+// slice holds the end of the part starting at each rune, or zero if no part
+// starts there, and covered reports whether each rune is within such a part.
+func passThroughs(runes []rune, ignored [][2]int, longest int,
+	known func(string) bool) (ends []int, covered []bool) {
+	ends = make([]int, len(runes))
+	covered = make([]bool, len(runes))
+
+	for _, span := range ignored {
+		ends[span[0]] = span[1]
+		for i := span[0]; i < span[1]; i++ {
+			covered[i] = true
+		}
+	}
+
+	for i := 0; i < len(runes); {
+		end := 0
+		if !covered[i] {
+			end = passThroughEnd(runes, i)
+		}
+
+		if end == 0 {
+			i++
+
+			continue
+		}
+
+		if unicode.IsDigit(runes[i]) && inKnownWord(runes, i, end, longest, known) {
+			i = end
+
+			continue
+		}
+
+		// Ignored parts are kept whole
+		for j := i; j < end; j++ {
+			if covered[j] {
+				end = j
+
+				break
+			}
+		}
+
+		ends[i] = end
+		for ; i < end; i++ {
+			covered[i] = true
+		}
+	}
+
+	return ends, covered
+}
+
+// inKnownWord reports whether the runes from start to end are part of a known
+// word of up to longest runes, within the word around them.
+func inKnownWord(runes []rune, start, end, longest int, known func(string) bool) bool {
+	wordStart, wordEnd := start, end
+	for wordStart > 0 && isWordRune(runes[wordStart-1]) {
+		wordStart--
+	}
+
+	for wordEnd < len(runes) && isWordRune(runes[wordEnd]) {
+		wordEnd++
+	}
+
+	for i := max(wordStart, end-longest); i <= start; i++ {
+		for j := end; j <= min(wordEnd, i+longest); j++ {
+			if (i < start || j > end) && known(string(runes[i:j])) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// urlPrefixes are the prefixes which start a URL.
+var urlPrefixes = []string{"http://", "https://", "www."}
+
+// passThroughEnd returns the end of the pass-through part starting at rune i,
+// or zero if there is none.
+func passThroughEnd(runes []rune, i int) int {
+	r := runes[i]
+
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case i == 0 || !isWordRune(runes[i-1]):
+		for _, prefix := range urlPrefixes {
+			if hasPrefixFold(runes[i:], prefix) {
+				end := i + len(prefix)
+				for end < len(runes) && !unicode.IsSpace(runes[end]) {
+					end++
+				}
+
+				// Punctuation at the end of a URL most likely ends the
+				// sentence around it
+				for end > i+len(prefix) && strings.ContainsRune(".,;:!?'\")", runes[end-1]) {
+					end--
+				}
+
+				return end
+			}
+		}
+	}
+
+	switch {
+	case unicode.IsDigit(r):
+		end := i + 1
+		for end < len(runes) {
+			if unicode.IsDigit(runes[end]) {
+				end++
+			} else if (runes[end] == '.' || runes[end] == ',') &&
+				end+1 < len(runes) && unicode.IsDigit(runes[end+1]) {
+				end += 2
+			} else {
+				break
+			}
+		}
+
+		return end
+	case isApostrophe(r) && i > 0 && i+1 < len(runes) &&
+		unicode.IsLetter(runes[i-1]) && unicode.IsLetter(runes[i+1]):
+		// An apostrophe within a word, such as a contraction
+		return 0
+	case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsControl(r) ||
+		unicode.Is(unicode.Cf, r):
+		// Include any modifiers and joined runes, which make up a single
+		// emoji
+		end := i + 1
+		for end < len(runes) {
+			next := runes[end]
+			if runes[end-1] == zeroWidthJoiner {
+				end++
+			} else if unicode.Is(unicode.M, next) || unicode.Is(unicode.Cf, next) ||
+				unicode.Is(unicode.Sk, next) {
+				end++
+			} else {
+				break
+			}
+		}
+
+		return end
+	}
+
+	return 0
+}
+
+const zeroWidthJoiner = '\u200d'
+
+// isWordRune reports whether r may be part of a word or number.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r)
+}
+
+// isApostrophe reports whether r is an apostrophe.
+func isApostrophe(r rune) bool {
+	return r == '\'' || r == '’'
+}
+
+// hasPrefixFold reports whether runes begins with prefix, ignoring case.
+func hasPrefixFold(runes []rune, prefix string) bool {
+	if len(runes) < len(prefix) {
+		return false
+	}
+
+	return strings.EqualFold(string(runes[:len(prefix)]), prefix)
+}
+
+// removeSpace returns s with all whitespace removed.
+func removeSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+
+		return r
+	}, s)
+}
+
+// segment finds up to k segmentations of the input, best first.
+func (s *Spell) segment(ctx context.Context, input string, k int, opts ...SegmentOption) ([]*SegmentResult, error) {
+	segmentParams := s.defaultSegmentParams()
+
+	for _, opt := range opts {
+		if err := opt(segmentParams); err != nil {
+			return nil, err
+		}
+	}
+
+	longestWord := int(atomic.LoadUint32(&s.longestWord))
+	if longestWord == 0 {
+		return nil, errors.New("longest word in dictionary has zero length")
+	}
+
+	// Determine the dictionary used by the lookups
+	lookupParams := s.defaultLookupParams()
+
+	for _, opt := range segmentParams.lookupOptions {
+		if err := opt(lookupParams); err != nil {
+			return nil, err
+		}
+	}
+
+	dict := lookupParams.dictOpts.name
+
+	// Known and unknown words are scored against the same total, which
+	// includes the add-one smoothing applied to each word of the dictionary
+	total := s.library.total(dict)
+
+	cumulativeFreq := float64(total.frequency + total.words)
+	if cumulativeFreq == 0 {
+		return nil, errors.New("cumulative frequency is zero")
+	}
+
+	runes := []rune(input)
+	inputLen := len(runes)
+
+	// offsets holds the byte offset of each rune of the input
+	offsets := make([]int, 0, inputLen+1)
+	for i := range input {
+		offsets = append(offsets, i)
+	}
+
+	offsets = append(offsets, len(input))
+
+	// The ignored parts of the input, converted to rune offsets
+	ignored := ignoredSpans(input, segmentParams.ignoreRules)
+	for i, span := range ignored {
+		ignored[i] = [2]int{sort.SearchInts(offsets, span[0]), sort.SearchInts(offsets, span[1])}
+	}
+
+	passThroughEnds, passThrough := passThroughs(runes, ignored, longestWord, func(word string) bool {
+		entry, exists := s.library.load(dict, word)
+
+		return exists && lookupParams.accept(entry)
+	})
+
+	// compositions[i] holds the best compositions of the first i runes of the
+	// input. Only the compositions within the longest word of the current
+	// position are needed, so earlier ones are released as the input is
+	// examined
+	compositions := make([][]composition, inputLen+1)
+	compositions[0] = []composition{{}}
+
+	// partial returns the best composition for the first i runes of the
+	// input, with the remainder of the input left unsegmented
+	partial := func(i int) ([]*SegmentResult, error) {
+		remainder := input[offsets[i]:]
+		c := compositions[i][0]
+		c.last = &segmentPart{
+			prev:  c.last,
+			input: remainder,
+			word:  remainder,
+			start: offsets[i],
+			end:   len(input),
+		}
+		c.distanceSum += inputLen - i
+
+		result, err := s.newSegmentResult(c, input, dict)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*SegmentResult{result}, interrupted(ctx)
+	}
+
+	for i := 0; i < inputLen; i++ {
+		// Positions within a pass-through part or whitespace are skipped
+		if len(compositions[i]) == 0 {
+			continue
+		}
+
+		if done(ctx) {
+			return partial(i)
+		}
+
+		// Pass-through parts are kept as they are
+		if end := passThroughEnds[i]; end > 0 {
+			for _, prev := range compositions[i] {
+				c := composition{
+					last: &segmentPart{
+						prev:        prev.last,
+						input:       input[offsets[i]:offsets[end]],
+						word:        input[offsets[i]:offsets[end]],
+						start:       offsets[i],
+						end:         offsets[end],
+						passThrough: true,
+					},
+					distanceSum: prev.distanceSum,
+					probability: prev.probability,
+					separators:  prev.separators,
+				}
+
+				compositions[end] = insertComposition(compositions[end], c, segmentParams.scorer, k)
+			}
+
+			compositions[i] = nil
+
+			continue
+		}
+
+		// Whitespace is skipped, and marks the end of the last word
+		if unicode.IsSpace(runes[i]) {
+			end := i + 1
+			for end < inputLen && unicode.IsSpace(runes[end]) && !passThrough[end] {
+				end++
+			}
+
+			for _, c := range compositions[i] {
+				c.spaced = true
+				compositions[end] = insertComposition(compositions[end], c, segmentParams.scorer, k)
+			}
+
+			compositions[i] = nil
+
+			continue
+		}
+
+		jMax := min(inputLen-i, longestWord)
+
+		for j := 1; j <= jMax; j++ {
+			// Words don't extend into pass-through parts, or end with
+			// whitespace
+			if passThrough[i+j-1] {
+				break
+			} else if unicode.IsSpace(runes[i+j-1]) {
+				continue
+			}
+
+			original := input[offsets[i]:offsets[i+j]]
+
+			var topEd int
+
+			var topResult string
+
+			part := removeSpace(original)
+			topEd += j - len([]rune(part))
+
+			suggestions, err := s.LookupContext(ctx, part, segmentParams.lookupOptions...)
+			if errors.Is(err, ErrInterrupted) {
+				return partial(i)
+			} else if err != nil {
+				return nil, err
+			}
+
+			w := SegmentWord{
+				Input:               part,
+				CumulativeFrequency: cumulativeFreq,
+			}
+
+			if len(suggestions) > 0 {
+				topResult = suggestions[0].Entry.Word
+				topEd += suggestions[0].Distance
+
+				w.Suggestion = &suggestions[0]
+				w.Probability = total.logPrior(suggestions[0].Frequency)
+			} else {
+				// Unknown word
+				topResult = part
+				topEd += len([]rune(part))
+			}
+
+			for _, prev := range compositions[i] {
+				if prev.last != nil {
+					w.Previous = prev.last.word
+				}
+
+				var partProbabilityLog float64
+
+				if w.Suggestion != nil {
+					// Score a known word by the probability of it following
+					// the last word of the composition it extends
+					if segmentParams.bigrams {
+						var context []string
+						if prev.last != nil {
+							context = []string{prev.last.word}
+						}
+
+						w.Probability = s.contextLogProb(dict, context, topResult)
+					}
+
+					partProbabilityLog = segmentParams.scorer.KnownWord(w)
+				} else {
+					partProbabilityLog = segmentParams.scorer.UnknownWord(w)
+				}
+
+				c := composition{
+					last: &segmentPart{
+						prev:  prev.last,
+						input: part,
+						word:  topResult,
+						start: offsets[i],
+						end:   offsets[i+j],
+					},
+					distanceSum: prev.distanceSum + topEd,
+					probability: prev.probability + partProbabilityLog,
+					separators:  prev.separators,
+				}
+
+				if prev.needsSeparator() {
+					c.distanceSum++
+					c.separatorLength = 1
+					c.separators++
+				}
+
+				compositions[i+j] = insertComposition(compositions[i+j], c, segmentParams.scorer, k)
+			}
+		}
+
+		compositions[i] = nil
+	}
+
+	results := make([]*SegmentResult, 0, len(compositions[inputLen]))
+
+	for _, c := range compositions[inputLen] {
+		result, err := s.newSegmentResult(c, input, dict)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// newSegmentResult creates the result for a composition of the input, looking
+// up the entry for each of its words in dict.
+func (s *Spell) newSegmentResult(c composition, input, dict string) (*SegmentResult, error) {
+	var parts []*segmentPart
+	for part := c.last; part != nil; part = part.prev {
+		parts = append(parts, part)
+	}
+
+	segments := make([]Segment, len(parts))
+
+	for i, part := range parts {
+		segment := Segment{
+			Input:       part.input,
+			Word:        part.word,
+			Start:       part.start,
+			End:         part.end,
+			PassThrough: part.passThrough,
+		}
+
+		if !part.passThrough {
+			e, err := s.GetEntry(part.word, DictionaryName(dict))
+			if err != nil {
+				return nil, err
+			}
+
+			segment.Entry = e
+		}
+
+		segments[len(parts)-1-i] = segment
+	}
+
+	result := SegmentResult{
+		Distance:    c.distanceSum,
+		Probability: c.probability,
+		Segments:    segments,
+		input:       input,
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,270 @@
+package spell_test
+
+import (
+	"testing"
+
+	"github.com/eskriett/spell"
+)
+
+func TestSegmentN(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"the": 10000, "rapist": 50, "therapist": 2, "rap": 40, "is": 5000,
+		"pist": 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := s.SegmentN("therapist", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, result := range results {
+		got = append(got, result.String())
+	}
+
+	expected := []string{"the rapist", "therapist"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+
+	// The split words are clearly more likely than the unsplit word
+	if margin := results[0].Probability - results[1].Probability; margin < 0.5 {
+		t.Fatalf("expected a margin of at least 0.5, got %f", margin)
+	}
+
+	// The best segmentation matches Segment
+	result, err := s.Segment("therapist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() != results[0].String() || result.Probability != results[0].Probability {
+		t.Fatalf("expected %v, got %v", results[0], result)
+	}
+
+	if _, err := s.SegmentN("therapist", 0); err == nil {
+		t.Fatal("expected error for zero segmentations")
+	}
+}
+
+// segmentKey returns the order of a segmentation within the results of
+// SegmentN: by its distance disregarding the separators added, then by its
+// probability and then by its distance.
+func segmentKey(r *spell.SegmentResult) (int, float64, int) {
+	separators := 0
+	for i := 1; i < len(r.Segments); i++ {
+		prev, seg := r.Segments[i-1], r.Segments[i]
+		if !prev.PassThrough && !seg.PassThrough && prev.End == seg.Start {
+			separators++
+		}
+	}
+
+	return r.Distance - separators, r.Probability, r.Distance
+}
+
+func TestSegmentN_sorted(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"the": 10000, "quick": 500, "quack": 50, "brown": 400, "brow": 300, "row": 200,
+		"fox": 300, "fix": 100, "jumps": 100, "jump": 200, "over": 800, "lazy": 100,
+		"lay": 300, "dog": 400, "dig": 100, "he": 2000, "ox": 50, "own": 600,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{"thequikbrownfoxjumpsoverthelazydog", "theqickbrwnfoxjmpsovrthlazydg", "hethebrownox"} {
+		results, err := s.SegmentN(input, 20)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i := 1; i < len(results); i++ {
+			d1, p1, r1 := segmentKey(results[i-1])
+			d2, p2, r2 := segmentKey(results[i])
+			if d1 > d2 || (d1 == d2 && (p1 < p2 || (p1 == p2 && r1 > r2))) {
+				t.Fatalf("%s: result %d %q (%d, %f, %d) is ranked above %q (%d, %f, %d)", input,
+					i-1, results[i-1], d1, p1, r1, results[i], d2, p2, r2)
+			}
+		}
+
+		// The best segmentation matches Segment
+		result, err := s.Segment(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.String() != results[0].String() {
+			t.Fatalf("%s: expected %q, got %q", input, results[0], result)
+		}
+	}
+}
+
+// wholeWordScorer prefers longer words by penalising every word equally.
+type wholeWordScorer struct {
+	spell.DefaultSegmentScorer
+}
+
+func (wholeWordScorer) KnownWord(w spell.SegmentWord) float64 {
+	return -1
+}
+
+func TestSegment_scoring(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"the": 10000, "rapist": 50, "therapist": 20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.Segment("therapist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() != "the rapist" {
+		t.Fatalf("expected %q, got %q", "the rapist", result.String())
+	}
+
+	result, err = s.Segment("therapist", spell.SegmentScoring(wholeWordScorer{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() != "therapist" {
+		t.Fatalf("expected %q, got %q", "therapist", result.String())
+	}
+
+	if _, err := s.Segment("therapist", spell.SegmentScoring(nil)); err == nil {
+		t.Fatal("expected error for nil scorer")
+	}
+}
+
+func TestSegment_passThrough(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"the": 10000, "rapist": 50, "therapist": 20, "see": 100, "hello": 100,
+		"world": 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := "helloworld, see https://example.com/a.b. 3.14 👍🏽"
+
+	result, err := s.Segment(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "hello world, see https://example.com/a.b. 3.14 👍🏽"
+	if result.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, result.String())
+	}
+
+	type segment struct {
+		word        string
+		original    string
+		passThrough bool
+	}
+
+	expectedSegments := []segment{
+		{"hello", "hello", false},
+		{"world", "world", false},
+		{",", ",", true},
+		{"see", "see", false},
+		{"https://example.com/a.b", "https://example.com/a.b", true},
+		{".", ".", true},
+		{"3.14", "3.14", true},
+		{"👍🏽", "👍🏽", true},
+	}
+
+	if len(result.Segments) != len(expectedSegments) {
+		t.Fatalf("expected %d segments, got %v", len(expectedSegments), result.GetWords())
+	}
+
+	for i, seg := range result.Segments {
+		got := segment{seg.Word, input[seg.Start:seg.End], seg.PassThrough}
+		if got != expectedSegments[i] {
+			t.Errorf("segment %d: expected %+v, got %+v", i, expectedSegments[i], got)
+		}
+	}
+
+	// Runs of whitespace aren't counted as edits, and are kept between words
+	result, err = s.Segment("  the   rapist ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() != "the   rapist" || result.Distance != 0 {
+		t.Fatalf("expected %q with distance 0, got %q with distance %d",
+			"the   rapist", result.String(), result.Distance)
+	}
+}
+
+func TestSegment_digits(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"mp3": 10, "player": 100, "track": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Digits within a word are part of it, while standalone numbers are
+	// passed through
+	result, err := s.Segment("mp3player track 12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.String(); got != "mp3 player track 12" || result.Distance != 1 {
+		t.Fatalf("expected %q with distance 1, got %q with distance %d",
+			"mp3 player track 12", got, result.Distance)
+	}
+
+	var passThrough []string
+	for _, seg := range result.Segments {
+		if seg.PassThrough {
+			passThrough = append(passThrough, seg.Word)
+		}
+	}
+	if len(passThrough) != 1 || passThrough[0] != "12" {
+		t.Fatalf("expected only 12 to be passed through, got %v", passThrough)
+	}
+
+	// Digits within words which aren't known are passed through unchanged
+	s, err = newWithWords(map[string]uint64{
+		"the": 1000, "fox": 100, "foxes": 100, "player": 100, "version": 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		input, expected, passThrough string
+	}{
+		{"the fox2", "the fox2", "2"},
+		{"the3foxes", "the3foxes", "3"},
+		{"thefox2", "the fox2", "2"},
+		{"version2", "version2", "2"},
+		{"mp3player", "mp3player", "3"},
+	}
+
+	for _, c := range cases {
+		result, err := s.Segment(c.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := result.String(); got != c.expected {
+			t.Errorf("%s: expected %q, got %q", c.input, c.expected, got)
+		}
+
+		var passThrough []string
+		for _, seg := range result.Segments {
+			if seg.PassThrough {
+				passThrough = append(passThrough, seg.Word)
+			}
+		}
+		if len(passThrough) != 1 || passThrough[0] != c.passThrough {
+			t.Errorf("%s: expected %s to be passed through, got %v", c.input, c.passThrough, passThrough)
+		}
+	}
+}
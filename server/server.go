@@ -0,0 +1,394 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+// Package server provides an http.Handler exposing a spell checker over JSON.
+//
+// The endpoints are:
+//
+//	GET    /lookup?word=w           suggestions for a word
+//	GET    /segment?text=t          the segmentation of text
+//	POST   /check                   misspellings within text
+//	GET    /entries/{word}          the entry for a word
+//	PUT    /entries/{word}          add or replace the entry for a word
+//	DELETE /entries/{word}          remove the entry for a word
+//	POST   /reload                  reload the dictionary file
+//
+// Every endpoint accepts a dictionary parameter, naming the dictionary to use
+// rather than the default. The lookup endpoint also accepts level (best,
+// closest or all), distance and max parameters, which control the suggestions
+// returned in the same way as the corresponding LookupOption. The check
+// endpoint accepts a JSON body holding the text and its format: text, html or
+// markdown. Errors are returned as a JSON object with an error field.
+//
+// Request bodies, and the text to segment, are limited to 1 MiB, and larger
+// requests fail with status 413. A lookup, segmentation or check which is
+// interrupted fails with status 503 if it ran out of time, or 499 if the client
+// went away.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/eskriett/spell"
+)
+
+// maxRequestBytes is the size limit of request bodies and of the text to
+// segment.
+const maxRequestBytes = 1 << 20
+
+// statusClientClosedRequest is the non-standard status of a request which was
+// abandoned by the client before it was served.
+const statusClientClosedRequest = 499
+
+// Server is an http.Handler serving a spell checker. It's safe for concurrent
+// use, including while its dictionary is reloaded.
+type Server struct {
+	spell    atomic.Pointer[spell.Spell]
+	filename string
+	mux      *http.ServeMux
+}
+
+// New creates a server for s. The server can't be reloaded.
+func New(s *spell.Spell) *Server {
+	srv := &Server{mux: http.NewServeMux()}
+	srv.spell.Store(s)
+
+	srv.mux.HandleFunc("GET /lookup", srv.lookup)
+	srv.mux.HandleFunc("GET /segment", srv.segment)
+	srv.mux.HandleFunc("POST /check", srv.check)
+	srv.mux.HandleFunc("GET /entries/{word}", srv.getEntry)
+	srv.mux.HandleFunc("PUT /entries/{word}", srv.putEntry)
+	srv.mux.HandleFunc("DELETE /entries/{word}", srv.deleteEntry)
+	srv.mux.HandleFunc("POST /reload", srv.reload)
+
+	return srv
+}
+
+// NewFromFile creates a server for the dictionary saved to disk at filename,
+// which is loaded again by Reload.
+func NewFromFile(filename string) (*Server, error) {
+	s, err := spell.Load(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := New(s)
+	srv.filename = filename
+
+	return srv, nil
+}
+
+// Spell returns the spell checker currently being served.
+func (srv *Server) Spell() *spell.Spell {
+	return srv.spell.Load()
+}
+
+// Reload loads the dictionary file again, and serves it once it's loaded.
+// Requests being served continue to use the previous dictionary, so no
+// requests fail while reloading. Changes made to entries since the dictionary
+// was last loaded are discarded.
+func (srv *Server) Reload() error {
+	if srv.filename == "" {
+		return errors.New("server has no dictionary file to reload")
+	}
+
+	s, err := spell.Load(srv.filename)
+	if err != nil {
+		return err
+	}
+
+	srv.spell.Store(s)
+
+	return nil
+}
+
+// ServeHTTP implements http.Handler.
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.mux.ServeHTTP(w, r)
+}
+
+// errorResponse is the body of a response to a failed request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeJSON writes v as the JSON body of a response with the given status.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes err as the body of a response with the given status.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// errorStatus returns the status of a response to a request which failed with
+// err. Requests interrupted by their context failed through no fault of their
+// own, while any other error is caused by the request.
+func errorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest
+	case errors.Is(err, spell.ErrInterrupted), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// dictionaryOpts returns the options selecting the dictionary named by the
+// request.
+func dictionaryOpts(r *http.Request) []spell.DictionaryOption {
+	if name := r.URL.Query().Get("dictionary"); name != "" {
+		return []spell.DictionaryOption{spell.DictionaryName(name)}
+	}
+
+	return nil
+}
+
+// lookupOptions returns the options for a lookup given by the request's
+// parameters.
+func lookupOptions(r *http.Request) ([]spell.LookupOption, error) {
+	query := r.URL.Query()
+	opts := []spell.LookupOption{spell.DictionaryOpts(dictionaryOpts(r)...)}
+
+	switch level := query.Get("level"); level {
+	case "", "best":
+	case "closest":
+		opts = append(opts, spell.SuggestionLevel(spell.LevelClosest))
+	case "all":
+		opts = append(opts, spell.SuggestionLevel(spell.LevelAll))
+	default:
+		return nil, fmt.Errorf("unknown level %q", level)
+	}
+
+	if distance := query.Get("distance"); distance != "" {
+		d, err := strconv.ParseUint(distance, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid distance: %w", err)
+		}
+
+		opts = append(opts, spell.EditDistance(uint32(d)))
+	}
+
+	if maxResults := query.Get("max"); maxResults != "" {
+		n, err := strconv.Atoi(maxResults)
+		if err != nil {
+			return nil, fmt.Errorf("invalid max: %w", err)
+		}
+
+		opts = append(opts, spell.MaxResults(n))
+	}
+
+	return opts, nil
+}
+
+// lookupResponse is the body of a response to a lookup.
+type lookupResponse struct {
+	Suggestions spell.SuggestionList `json:"suggestions"`
+}
+
+func (srv *Server) lookup(w http.ResponseWriter, r *http.Request) {
+	word := r.URL.Query().Get("word")
+	if word == "" {
+		writeError(w, http.StatusBadRequest, errors.New("word must be given"))
+
+		return
+	}
+
+	opts, err := lookupOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	// The response includes the score and probability of each suggestion
+	opts = append(opts, spell.Probabilities())
+
+	suggestions, err := srv.Spell().LookupContext(r.Context(), word, opts...)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, lookupResponse{Suggestions: suggestions})
+}
+
+func (srv *Server) segment(w http.ResponseWriter, r *http.Request) {
+	text := r.URL.Query().Get("text")
+	if text == "" {
+		writeError(w, http.StatusBadRequest, errors.New("text must be given"))
+
+		return
+	}
+
+	if len(text) > maxRequestBytes {
+		writeError(w, http.StatusRequestEntityTooLarge,
+			fmt.Errorf("text must not be longer than %d bytes", maxRequestBytes))
+
+		return
+	}
+
+	result, err := srv.Spell().SegmentContext(r.Context(), text, spell.SegmentLookupOpts(
+		spell.DictionaryOpts(dictionaryOpts(r)...),
+		spell.SuggestionLevel(spell.LevelBest),
+	))
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// checkRequest is the body of a request to check text.
+type checkRequest struct {
+	Text string `json:"text"`
+
+	// The format of the text: text, html or markdown. Defaults to text
+	Format string `json:"format"`
+}
+
+// checkResponse is the body of a response to a check.
+type checkResponse struct {
+	Misspellings []spell.Misspelling `json:"misspellings"`
+}
+
+func (srv *Server) check(w http.ResponseWriter, r *http.Request) {
+	var req checkRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, errorStatus(err), fmt.Errorf("invalid request: %w", err))
+
+		return
+	}
+
+	opts, err := lookupOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if r.URL.Query().Get("level") == "" {
+		opts = append(opts, spell.SuggestionLevel(spell.LevelClosest))
+	}
+
+	s := srv.Spell()
+	checkOpts := spell.CheckLookupOpts(opts...)
+
+	var misspellings []spell.Misspelling
+
+	switch req.Format {
+	case "", "text":
+		misspellings, err = s.CheckTextContext(r.Context(), req.Text, checkOpts)
+	case "html":
+		misspellings, err = s.CheckHTMLContext(r.Context(), req.Text, checkOpts)
+	case "markdown":
+		misspellings, err = s.CheckMarkdownContext(r.Context(), req.Text, checkOpts)
+	default:
+		err = fmt.Errorf("unknown format %q", req.Format)
+	}
+
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+
+		return
+	}
+
+	if misspellings == nil {
+		misspellings = []spell.Misspelling{}
+	}
+
+	writeJSON(w, http.StatusOK, checkResponse{Misspellings: misspellings})
+}
+
+func (srv *Server) getEntry(w http.ResponseWriter, r *http.Request) {
+	word := r.PathValue("word")
+
+	entry, err := srv.Spell().GetEntry(word, dictionaryOpts(r)...)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if entry == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no entry for %q", word))
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entry)
+}
+
+func (srv *Server) putEntry(w http.ResponseWriter, r *http.Request) {
+	var entry spell.Entry
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&entry); err != nil {
+		writeError(w, errorStatus(err), fmt.Errorf("invalid entry: %w", err))
+
+		return
+	}
+
+	// The word is given by the path
+	entry.Word = r.PathValue("word")
+
+	added, err := srv.Spell().AddEntry(entry, dictionaryOpts(r)...)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+
+	writeJSON(w, status, entry)
+}
+
+func (srv *Server) deleteEntry(w http.ResponseWriter, r *http.Request) {
+	word := r.PathValue("word")
+
+	removed, err := srv.Spell().RemoveEntry(word, dictionaryOpts(r)...)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+
+		return
+	}
+
+	if !removed {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no entry for %q", word))
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (srv *Server) reload(w http.ResponseWriter, r *http.Request) {
+	if err := srv.Reload(); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
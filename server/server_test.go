@@ -0,0 +1,286 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eskriett/spell"
+	"github.com/eskriett/spell/server"
+)
+
+func newSpell(t *testing.T) *spell.Spell {
+	t.Helper()
+
+	s := spell.New()
+
+	for word, freq := range map[string]uint64{"two": 100, "town": 10, "the": 1000, "quick": 100} {
+		if _, err := s.AddEntry(spell.Entry{Word: word, Frequency: freq}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := s.AddEntry(spell.Entry{Word: "colour", Frequency: 10}, spell.DictionaryName("british")); err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+// do performs a request against ts, decoding the JSON response into v if it's
+// not nil.
+func do(t *testing.T, ts *httptest.Server, method, path, body string, v interface{}) int {
+	t.Helper()
+
+	req, err := http.NewRequest(method, ts.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := ts.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return resp.StatusCode
+}
+
+type suggestions struct {
+	Suggestions []struct {
+		Word     string
+		Distance int
+	}
+}
+
+func TestLookup(t *testing.T) {
+	ts := httptest.NewServer(server.New(newSpell(t)))
+	defer ts.Close()
+
+	var got suggestions
+	if status := do(t, ts, "GET", "/lookup?word=twon&level=all", "", &got); status != http.StatusOK {
+		t.Fatalf("unexpected status %d", status)
+	}
+
+	if len(got.Suggestions) != 2 || got.Suggestions[0].Word != "two" || got.Suggestions[1].Word != "town" {
+		t.Fatalf("unexpected suggestions %+v", got)
+	}
+
+	if status := do(t, ts, "GET", "/lookup?word=color&dictionary=british", "", &got); status != http.StatusOK {
+		t.Fatalf("unexpected status %d", status)
+	}
+
+	if len(got.Suggestions) != 1 || got.Suggestions[0].Word != "colour" {
+		t.Fatalf("unexpected suggestions %+v", got)
+	}
+
+	var errResp struct{ Error string }
+	if status := do(t, ts, "GET", "/lookup?word=twon&level=some", "", &errResp); status != http.StatusBadRequest ||
+		errResp.Error == "" {
+		t.Fatalf("expected bad request with an error, got %d %+v", status, errResp)
+	}
+}
+
+func TestSegmentCheck(t *testing.T) {
+	ts := httptest.NewServer(server.New(newSpell(t)))
+	defer ts.Close()
+
+	var result struct{ Segments []struct{ Word string } }
+	if status := do(t, ts, "GET", "/segment?text="+url.QueryEscape("thequick"), "", &result); status != http.StatusOK {
+		t.Fatalf("unexpected status %d", status)
+	}
+
+	if len(result.Segments) != 2 || result.Segments[1].Word != "quick" {
+		t.Fatalf("unexpected segments %+v", result)
+	}
+
+	var checked struct {
+		Misspellings []struct {
+			Start, End  int
+			Word        string
+			Suggestions []struct{ Word string }
+		}
+	}
+
+	status := do(t, ts, "POST", "/check", `{"text": "<p>the <b>quikc</b> twon</p>", "format": "html"}`, &checked)
+	if status != http.StatusOK {
+		t.Fatalf("unexpected status %d", status)
+	}
+
+	if len(checked.Misspellings) != 2 || checked.Misspellings[0].Word != "quikc" ||
+		checked.Misspellings[0].Start != 10 || checked.Misspellings[1].Suggestions[0].Word != "two" {
+		t.Fatalf("unexpected misspellings %+v", checked)
+	}
+}
+
+func TestSegmentCheck_errors(t *testing.T) {
+	srv := server.New(newSpell(t))
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	// Requests larger than the limit are rejected
+	long := strings.Repeat("the ", 1<<18+1)
+
+	if status := do(t, ts, "POST", "/check", `{"text": "`+long+`"}`, nil); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, status)
+	}
+
+	if status := do(t, ts, "GET", "/segment?text="+strings.Repeat("x", 1<<20+1), "", nil); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, status)
+	}
+
+	// Bad input is still a bad request
+	if status := do(t, ts, "POST", "/check", `{"text": "the", "format": "pdf"}`, nil); status != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, status)
+	}
+
+	// Interrupted requests fail without blaming the request
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+
+	cases := []struct {
+		ctx    context.Context
+		status int
+	}{
+		{canceled, 499},
+		{expired, http.StatusServiceUnavailable},
+	}
+
+	for _, c := range cases {
+		req := httptest.NewRequest("POST", "/check", strings.NewReader(`{"text": "the quikc twon"}`))
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req.WithContext(c.ctx))
+
+		if rec.Code != c.status {
+			t.Errorf("check: expected status %d, got %d", c.status, rec.Code)
+		}
+
+		req = httptest.NewRequest("GET", "/segment?text=thequikctwon", nil)
+		rec = httptest.NewRecorder()
+		srv.ServeHTTP(rec, req.WithContext(c.ctx))
+
+		if rec.Code != c.status {
+			t.Errorf("segment: expected status %d, got %d", c.status, rec.Code)
+		}
+	}
+}
+
+func TestEntries(t *testing.T) {
+	ts := httptest.NewServer(server.New(newSpell(t)))
+	defer ts.Close()
+
+	if status := do(t, ts, "GET", "/entries/gopher", "", nil); status != http.StatusNotFound {
+		t.Fatalf("expected not found, got %d", status)
+	}
+
+	var entry spell.Entry
+	if status := do(t, ts, "PUT", "/entries/gopher", `{"Frequency": 5}`, &entry); status != http.StatusCreated {
+		t.Fatalf("expected created, got %d", status)
+	}
+
+	if status := do(t, ts, "PUT", "/entries/gopher", `{"Frequency": 7}`, &entry); status != http.StatusOK {
+		t.Fatalf("expected ok, got %d", status)
+	}
+
+	if status := do(t, ts, "GET", "/entries/gopher", "", &entry); status != http.StatusOK ||
+		entry.Word != "gopher" || entry.Frequency != 7 {
+		t.Fatalf("unexpected entry %d %+v", status, entry)
+	}
+
+	if status := do(t, ts, "GET", "/entries/gopher?dictionary=british", "", nil); status != http.StatusNotFound {
+		t.Fatalf("expected not found, got %d", status)
+	}
+
+	if status := do(t, ts, "DELETE", "/entries/gopher", "", nil); status != http.StatusNoContent {
+		t.Fatalf("expected no content, got %d", status)
+	}
+
+	if status := do(t, ts, "DELETE", "/entries/gopher", "", nil); status != http.StatusNotFound {
+		t.Fatalf("expected not found, got %d", status)
+	}
+}
+
+func TestReload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.spell")
+
+	s := newSpell(t)
+	if err := s.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := server.NewFromFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	if _, err := s.AddEntry(spell.Entry{Word: "gopher", Frequency: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lookups continue to succeed while the dictionary is reloaded
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				resp, err := ts.Client().Get(ts.URL + "/lookup?word=twon")
+				if err != nil {
+					t.Error(err)
+
+					return
+				}
+
+				var got suggestions
+				err = json.NewDecoder(resp.Body).Decode(&got)
+				resp.Body.Close()
+
+				if err != nil || resp.StatusCode != http.StatusOK || len(got.Suggestions) != 1 {
+					t.Errorf("unexpected response %d %+v %v", resp.StatusCode, got, err)
+				}
+			}
+		}()
+	}
+
+	if status := do(t, ts, "POST", "/reload", "", nil); status != http.StatusNoContent {
+		t.Fatalf("expected no content, got %d", status)
+	}
+
+	wg.Wait()
+
+	var entry spell.Entry
+	if status := do(t, ts, "GET", "/entries/gopher", "", &entry); status != http.StatusOK {
+		t.Fatalf("expected the reloaded entry, got %d", status)
+	}
+
+	if err := server.New(s).Reload(); err == nil {
+		t.Fatal("expected error reloading a server without a file")
+	}
+}
@@ -7,17 +7,19 @@ package spell
 
 import (
 	"compress/gzip"
+	"container/heap"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
-	"log"
 	"math"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
-	"unicode"
 
 	"github.com/eskriett/strmet"
 	"github.com/mitchellh/mapstructure"
@@ -25,6 +27,7 @@ import (
 )
 
 type (
+	mergeStrategy   int
 	suggestionLevel int
 	deletes         map[uint32]struct{}
 )
@@ -41,6 +44,28 @@ const (
 	LevelAll
 )
 
+// Merge Strategies used when a Lookup spans multiple dictionaries.
+const (
+	// MergeFirstHit will yield suggestions from the first dictionary, in
+	// priority order, that has any.
+	MergeFirstHit mergeStrategy = iota
+
+	// MergeFrequencySum will combine suggestions for the same word from each
+	// dictionary, summing their frequencies weighted by DictionaryWeight.
+	MergeFrequencySum
+
+	// MergeBoost will multiply the frequency of each suggestion by the
+	// DictionaryWeight of its dictionary, keeping the most boosted suggestion
+	// for each word.
+	MergeBoost
+)
+
+// ErrInterrupted is returned by LookupContext and SegmentContext when their
+// context is done before the operation completes. The error also wraps the
+// context's error, and the results returned alongside it are the best found so
+// far.
+var ErrInterrupted = errors.New("operation interrupted before completion")
+
 const (
 	defaultDict         = "default"
 	defaultEditDistance = 2
@@ -60,6 +85,7 @@ type Spell struct {
 	dictionaryDeletes *dictionaryDeletes
 	longestWord       uint32
 	library           *library
+	ngrams            *ngramModel
 }
 
 // WordData stores metadata about a word.
@@ -81,6 +107,7 @@ func New() *Spell {
 	s.MaxEditDistance = defaultEditDistance
 	s.PrefixLength = defaultPrefixLength
 	s.library = newLibrary()
+	s.ngrams = newNGramModel()
 
 	return s
 }
@@ -88,8 +115,6 @@ func New() *Spell {
 // Load a dictionary from disk from filename. Returns a new Spell instance on
 // success, or will return an error if there's a problem reading the file.
 func Load(filename string) (*Spell, error) {
-	s := New()
-
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
@@ -97,11 +122,15 @@ func Load(filename string) (*Spell, error) {
 
 	gz, err := gzip.NewReader(f)
 	if err != nil {
+		f.Close()
+
 		return nil, err
 	}
 
-	data, err := io.ReadAll(gz)
+	s, err := Read(gz)
 	if err != nil {
+		f.Close()
+
 		return nil, err
 	}
 
@@ -115,10 +144,27 @@ func Load(filename string) (*Spell, error) {
 		return nil, err
 	}
 
+	return s, nil
+}
+
+// Read a dictionary from r, in the uncompressed format written by Write.
+// Returns a new Spell instance on success, or will return an error if there's
+// a problem reading or decoding the dictionary.
+func Read(r io.Reader) (*Spell, error) {
+	s := New()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if !gjson.ValidBytes(data) {
+		return nil, errors.New("invalid dictionary")
+	}
+
 	// Load the words
 	gj := gjson.ParseBytes(data)
-	
-	
+
 	if gj.Get("options.editDistance").Exists() {
 		s.MaxEditDistance = uint32(gj.Get("options.editDistance").Int())
 	}
@@ -126,17 +172,30 @@ func Load(filename string) (*Spell, error) {
 	if gj.Get("options.prefixLength").Exists() {
 		s.PrefixLength = uint32(gj.Get("options.prefixLength").Int())
 	}
-	
+
 	gj.Get("words").ForEach(func(dictionary, entries gjson.Result) bool {
 		entries.ForEach(func(word, definition gjson.Result) bool {
 			e := Entry{}
-			if err := mapstructure.Decode(definition.Value(), &e); err != nil {
-				log.Fatal(err)
+			if err = mapstructure.Decode(definition.Value(), &e); err != nil {
+				return false
 			}
 
-			if _, err := s.AddEntry(e); err != nil {
-				log.Fatal(err)
-			}
+			_, err = s.AddEntry(e, DictionaryName(dictionary.String()))
+
+			return err == nil
+		})
+
+		return err == nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Load the n-grams
+	gj.Get("ngrams").ForEach(func(dictionary, counts gjson.Result) bool {
+		counts.ForEach(func(ngram, count gjson.Result) bool {
+			s.ngrams.add(dictionary.String(), strings.Split(ngram.String(), ngramSeparator), count.Uint())
 
 			return true
 		})
@@ -263,38 +322,71 @@ func (s *Spell) RemoveEntry(word string, opts ...DictionaryOption) (bool, error)
 
 // Save a representation of spell to disk at filename.
 func (s *Spell) Save(filename string) error {
-	jsonStr, _ := json.Marshal(map[string]interface{}{
-		"options": map[string]interface{}{
-			"editDistance": s.MaxEditDistance,
-			"prefixLength": s.PrefixLength,
-		},
-		"words": s.library.dictionaries,
-	})
-
 	f, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
 
 	w := gzip.NewWriter(f)
-	_, err = w.Write(jsonStr)
 
+	err = s.Write(w)
 	if err != nil {
+		f.Close()
+
 		return err
 	}
 
 	err = w.Close()
+	if err != nil {
+		f.Close()
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// Write an uncompressed representation of spell to w, which can be read by
+// Read.
+func (s *Spell) Write(w io.Writer) error {
+	jsonStr, err := json.Marshal(map[string]interface{}{
+		"options": map[string]interface{}{
+			"editDistance": s.MaxEditDistance,
+			"prefixLength": s.PrefixLength,
+		},
+		"words":  s.library.snapshot(),
+		"ngrams": s.ngrams.snapshot(),
+	})
+
 	if err != nil {
 		return err
 	}
 
-	return nil
+	_, err = w.Write(jsonStr)
+
+	return err
 }
 
 // Suggestion is used to represent a suggested word from a lookup.
 type Suggestion struct {
 	// The distance between this suggestion and the input word
 	Distance int
+
+	// The name of the dictionary the suggestion came from
+	Dictionary string
+
+	// The log10 probability of the suggestion being the intended word, as
+	// estimated by a noisy channel model. Only set when the lookup uses
+	// Probabilities, RankByScore or Explain
+	Score float64
+
+	// The probability of the suggestion being the intended word relative to
+	// the other words considered during the lookup. Like Score, only set when
+	// the lookup uses Probabilities, RankByScore or Explain
+	Probability float64
+
+	// Details of how the suggestion was found, if the lookup was explained
+	Explanation *Explanation `json:",omitempty"`
 	Entry
 }
 
@@ -316,11 +408,91 @@ func (s SuggestionList) String() string {
 	return "[" + strings.Join(s.GetWords(), ", ") + "]"
 }
 
+// lessSuggestion orders suggestions by their edit distance, then their
+// frequency.
+func lessSuggestion(s1, s2 Suggestion) bool {
+	if s1.Distance < s2.Distance {
+		return true
+	} else if s1.Distance == s2.Distance {
+		return s1.Frequency > s2.Frequency
+	}
+
+	return false
+}
+
+// suggestionHeap is a heap of suggestions with the worst suggestion at its
+// root. It's used to retain the best n suggestions during a lookup without
+// having to sort every suggestion found.
+type suggestionHeap struct {
+	SuggestionList
+	less func(Suggestion, Suggestion) bool
+}
+
+func (h *suggestionHeap) Len() int { return len(h.SuggestionList) }
+
+func (h *suggestionHeap) Less(i, j int) bool {
+	return h.less(h.SuggestionList[j], h.SuggestionList[i])
+}
+
+func (h *suggestionHeap) Swap(i, j int) {
+	h.SuggestionList[i], h.SuggestionList[j] = h.SuggestionList[j], h.SuggestionList[i]
+}
+
+func (h *suggestionHeap) Push(x interface{}) {
+	h.SuggestionList = append(h.SuggestionList, x.(Suggestion))
+}
+
+func (h *suggestionHeap) Pop() interface{} {
+	old := h.SuggestionList
+	n := len(old)
+	sugg := old[n-1]
+	h.SuggestionList = old[:n-1]
+
+	return sugg
+}
+
+// pushBounded adds sugg to results, which must be heap ordered by less,
+// retaining at most n suggestions. If results is full, sugg replaces the worst
+// suggestion only if it is better. The suggestion which didn't make it into
+// the results, if any, is also returned.
+func pushBounded(results SuggestionList, sugg Suggestion, n int,
+	less func(Suggestion, Suggestion) bool,
+) (SuggestionList, *Suggestion) {
+	h := suggestionHeap{results, less}
+
+	if h.Len() < n {
+		heap.Push(&h, sugg)
+
+		return h.SuggestionList, nil
+	}
+
+	if less(sugg, h.SuggestionList[0]) {
+		worst := h.SuggestionList[0]
+		h.SuggestionList[0] = sugg
+		heap.Fix(&h, 0)
+
+		return h.SuggestionList, &worst
+	}
+
+	return h.SuggestionList, &sugg
+}
+
 type lookupParams struct {
+	dictionaries     []string
 	dictOpts         *dictOptions
+	dictWeights      map[string]float64
 	distanceFunction func([]rune, []rune, int) int
 	editDistance     uint32
+	errorModel       ErrorModel
+	explain          bool
+	filters          []func(Entry) bool
+	maxResults       int
+	mergeStrategy    mergeStrategy
+	minFrequency     uint64
 	prefixLength     uint32
+	prefixMatch      bool
+	rankByScore      bool
+	scores           bool
 	sortFunc         func(SuggestionList)
 	suggestionLevel  suggestionLevel
 }
@@ -330,19 +502,11 @@ func (s *Spell) defaultLookupParams() *lookupParams {
 		dictOpts:         s.defaultDictOptions(),
 		distanceFunction: strmet.DamerauLevenshteinRunes,
 		editDistance:     s.MaxEditDistance,
+		errorModel:       defaultErrorModel,
 		prefixLength:     s.PrefixLength,
 		sortFunc: func(results SuggestionList) {
 			sort.Slice(results, func(i, j int) bool {
-				s1 := results[i]
-				s2 := results[j]
-
-				if s1.Distance < s2.Distance {
-					return true
-				} else if s1.Distance == s2.Distance {
-					return s1.Frequency > s2.Frequency
-				}
-
-				return false
+				return lessSuggestion(results[i], results[j])
 			})
 		},
 		suggestionLevel: LevelBest,
@@ -367,6 +531,57 @@ func DictionaryOpts(opts ...DictionaryOption) LookupOption {
 	}
 }
 
+// Dictionaries allows a Lookup to span multiple dictionaries, given in order of
+// priority. How suggestions from each dictionary are combined is controlled by
+// MergeStrategy. When set, any dictionary given by DictionaryOpts is ignored.
+func Dictionaries(names ...string) LookupOption {
+	return func(lp *lookupParams) error {
+		if len(names) == 0 {
+			return errors.New("at least one dictionary must be given")
+		}
+
+		lp.dictionaries = names
+
+		return nil
+	}
+}
+
+// DictionaryWeight sets the weight applied to frequencies from the named
+// dictionary by the MergeFrequencySum and MergeBoost strategies. Dictionaries
+// have a weight of 1 by default.
+func DictionaryWeight(name string, weight float64) LookupOption {
+	return func(lp *lookupParams) error {
+		if weight < 0 {
+			return errors.New("dictionary weight must not be negative")
+		}
+
+		if lp.dictWeights == nil {
+			lp.dictWeights = make(map[string]float64)
+		}
+
+		lp.dictWeights[name] = weight
+
+		return nil
+	}
+}
+
+// MergeStrategy defines how suggestions are combined when a Lookup spans
+// multiple dictionaries. See the package constants for the strategies
+// available. By default, MergeFirstHit is used.
+func MergeStrategy(strategy mergeStrategy) LookupOption {
+	return func(lp *lookupParams) error {
+		switch strategy {
+		case MergeFirstHit, MergeFrequencySum, MergeBoost:
+		default:
+			return errors.New("unknown merge strategy")
+		}
+
+		lp.mergeStrategy = strategy
+
+		return nil
+	}
+}
+
 // DistanceFunc accepts a function, f(str1, str2, maxDist), which calculates the
 // distance between two strings. It should return -1 if the distance between the
 // strings is greater than maxDist.
@@ -388,6 +603,104 @@ func EditDistance(dist uint32) LookupOption {
 	}
 }
 
+// Filter excludes words from the suggestions returned by the Lookup unless f
+// returns true for their Entry. Filters are applied before a word is compared
+// with the suggestions found so far, so a filtered word never displaces a
+// better match. When multiple filters are given, a word must pass all of them.
+func Filter(f func(Entry) bool) LookupOption {
+	return func(lp *lookupParams) error {
+		if f == nil {
+			return errors.New("filter must not be nil")
+		}
+
+		lp.filters = append(lp.filters, f)
+
+		return nil
+	}
+}
+
+// WordDataEquals restricts the suggestions returned by the Lookup to words
+// whose WordData has key set to value. Numbers are compared by value whatever
+// their type, so that an int matches the float64 it's decoded as once a
+// dictionary is saved and loaded.
+func WordDataEquals(key string, value interface{}) LookupOption {
+	return Filter(func(e Entry) bool {
+		v, exists := e.WordData[key]
+
+		return exists && wordDataEqual(v, value)
+	})
+}
+
+// wordDataEqual reports whether two WordData values are equal, comparing
+// numbers by value.
+func wordDataEqual(a, b interface{}) bool {
+	if x, ok := numericValue(a); ok {
+		y, ok := numericValue(b)
+
+		return ok && x == y
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// numericValue returns v as a float64 if it's a number.
+func numericValue(v interface{}) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+
+		return f, err == nil
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+
+	return 0, false
+}
+
+// WordDataHas restricts the suggestions returned by the Lookup to words whose
+// WordData contains key.
+func WordDataHas(key string) LookupOption {
+	return Filter(func(e Entry) bool {
+		_, exists := e.WordData[key]
+
+		return exists
+	})
+}
+
+// MaxResults limits the number of suggestions returned by the Lookup to the n
+// best, as ordered by their edit distance and then their frequency. Only the
+// best n suggestions are retained while candidates are examined, so a custom
+// SortFunc is applied to those that remain.
+func MaxResults(n int) LookupOption {
+	return func(lp *lookupParams) error {
+		if n < 1 {
+			return errors.New("max results must be greater than 0")
+		}
+
+		lp.maxResults = n
+
+		return nil
+	}
+}
+
+// MinFrequency excludes words with a frequency lower than freq from the
+// suggestions returned by the Lookup.
+func MinFrequency(freq uint64) LookupOption {
+	return func(lp *lookupParams) error {
+		lp.minFrequency = freq
+
+		return nil
+	}
+}
+
 // SortFunc allows the sorting of the SuggestionList to be configured. By
 // default, suggestions will be sorted by their edit distance, then their
 // frequency.
@@ -423,12 +736,98 @@ func PrefixLength(prefixLength uint32) LookupOption {
 	}
 }
 
-func (s *Spell) newDictSuggestion(input string, dist int, dp *dictOptions) Suggestion {
-	entry, _ := s.library.load(dp.name, input)
+// PrefixMatch compares the input with the start of each word in the dictionary
+// rather than the whole word, so words are suggested if they start with
+// something close to the input. This allows type-ahead input to be corrected.
+// The distance of each suggestion is that of the closest prefix of the word.
+func PrefixMatch() LookupOption {
+	return func(lp *lookupParams) error {
+		lp.prefixMatch = true
+
+		return nil
+	}
+}
+
+// completions returns the number of completions needed to satisfy the
+// suggestion level and maximum results of the lookup. Every completion is
+// needed to explain which were dropped.
+func (lp *lookupParams) completions() int {
+	switch {
+	case lp.explain:
+		return math.MaxInt
+	case lp.suggestionLevel == LevelBest:
+		return 1
+	case lp.maxResults > 0:
+		return lp.maxResults
+	}
+
+	return math.MaxInt
+}
+
+// prefixDistance returns the smallest distance between input and a prefix of
+// word, as calculated by df, along with the length of that prefix. Returns -1
+// for both if no prefix is within maxDist.
+func prefixDistance(df func([]rune, []rune, int) int, input, word []rune, maxDist int) (int, int) {
+	bestDist, bestLen := -1, -1
+
+	for k := max(0, len(input)-maxDist); k <= min(len(word), len(input)+maxDist); k++ {
+		if dist := df(input, word[:k], maxDist); dist >= 0 && (bestDist < 0 || dist < bestDist) {
+			bestDist, bestLen = dist, k
+		}
+	}
+
+	return bestDist, bestLen
+}
+
+// accept reports whether an entry satisfies the frequency and filter
+// constraints of the lookup.
+func (lp *lookupParams) accept(entry Entry) bool {
+	if entry.Frequency < lp.minFrequency {
+		return false
+	}
+
+	for _, f := range lp.filters {
+		if !f(entry) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// scoring reports whether suggestions are scored during the lookup. Scoring
+// compares every suggestion with the input edit by edit, so it's avoided
+// unless needed.
+func (lp *lookupParams) scoring() bool {
+	return lp.scores || lp.rankByScore || lp.explain
+}
+
+// less returns the function used to order suggestions while they are
+// collected.
+func (lp *lookupParams) less() func(Suggestion, Suggestion) bool {
+	if lp.rankByScore {
+		return lessScore
+	}
+
+	return lessSuggestion
+}
+
+// weight returns the weight applied to frequencies from the named dictionary.
+func (lp *lookupParams) weight(dict string) float64 {
+	if weight, exists := lp.dictWeights[dict]; exists {
+		return weight
+	}
+
+	return 1
+}
+
+func (s *Spell) newDictSuggestion(input string, dist int, dict string) Suggestion {
+	entry, _ := s.library.load(dict, input)
 
 	return Suggestion{
-		Distance: dist,
-		Entry:    entry,
+		Distance:   dist,
+		Dictionary: dict,
+		Entry:      entry,
 	}
 }
 
@@ -439,6 +838,13 @@ func (s *Spell) newDictSuggestion(input string, dist int, dp *dictOptions) Sugge
 // Accepts zero or more LookupOption that can be used to configure how lookup
 // occurs.
 func (s *Spell) Lookup(input string, opts ...LookupOption) (SuggestionList, error) {
+	return s.LookupContext(context.Background(), input, opts...)
+}
+
+// LookupContext is like Lookup but stops examining candidates once ctx is done.
+// In that case the suggestions found so far are returned along with an error
+// wrapping both ErrInterrupted and the context's error.
+func (s *Spell) LookupContext(ctx context.Context, input string, opts ...LookupOption) (SuggestionList, error) {
 	lookupParams := s.defaultLookupParams()
 
 	for _, opt := range opts {
@@ -447,371 +853,560 @@ func (s *Spell) Lookup(input string, opts ...LookupOption) (SuggestionList, erro
 		}
 	}
 
-	results := SuggestionList{}
-	dict := lookupParams.dictOpts.name
+	var (
+		results SuggestionList
+		err     error
+	)
 
-	// Check for an exact match
-	if _, exists := s.library.load(dict, input); exists {
-		results = append(results, s.newDictSuggestion(input, 0, lookupParams.dictOpts))
+	if len(lookupParams.dictionaries) > 0 {
+		results, err = s.lookupDictionaries(ctx, input, lookupParams)
+	} else {
+		results, err = s.lookupDictionary(ctx, input, lookupParams.dictOpts.name, lookupParams)
+	}
 
-		if lookupParams.suggestionLevel != LevelAll {
-			return results, nil
-		}
+	// Order the results
+	if len(results) > 1 {
+		lookupParams.sortFunc(results)
 	}
 
-	editDistance := int(lookupParams.editDistance)
+	return results, err
+}
+
+// lookupDictionaries performs a lookup across each of the dictionaries in
+// lookupParams, merging their suggestions using its merge strategy.
+func (s *Spell) lookupDictionaries(ctx context.Context, input string, lookupParams *lookupParams) (SuggestionList, error) {
+	if lookupParams.mergeStrategy == MergeFirstHit {
+		for _, dict := range lookupParams.dictionaries {
+			results, err := s.lookupDictionary(ctx, input, dict, lookupParams)
+			if err != nil || len(results) > 0 {
+				return results, err
+			}
+		}
 
-	// If edit distance is 0, just check if input is in the dictionary
-	if editDistance == 0 {
-		return results, nil
+		return SuggestionList{}, nil
 	}
 
-	inputRunes := []rune(input)
-	inputLen := len(inputRunes)
-	prefixLength := int(lookupParams.prefixLength)
+	// Every suggestion at the closest distance is needed from each
+	// dictionary, as a word's merged frequency may make it the best overall.
+	// For the same reason, the number of results is only limited once merged
+	dictParams := *lookupParams
+	if dictParams.suggestionLevel == LevelBest {
+		dictParams.suggestionLevel = LevelClosest
+	}
 
-	// Keep track of the deletes we've already considered
-	consideredDeletes := make(map[string]struct{})
+	dictParams.maxResults = 0
 
-	// Keep track of the suggestions we've already considered
-	consideredSuggestions := make(map[string]struct{})
-	consideredSuggestions[input] = struct{}{}
+	var (
+		err   error
+		words []string
+	)
 
-	// Keep a list of words we want to try
-	var candidates []string
+	distances := make(map[string]int)
+	explanations := make(map[string]*Explanation)
 
-	// Restrict the length of the input we'll examine
-	inputPrefixLen := min(inputLen, prefixLength)
-	candidates = append(candidates, substring(input, 0, inputPrefixLen))
+	var dropped []DroppedSuggestion
 
-	for i := 0; i < len(candidates); i++ {
-		candidate := candidates[i]
-		candidateLen := len([]rune(candidate))
-		lengthDiff := inputPrefixLen - candidateLen
+	for _, dict := range lookupParams.dictionaries {
+		var results SuggestionList
 
-		// If the difference between the prefixed input and candidate is larger
-		// than the max edit distance then skip the candidate
-		if lengthDiff > editDistance {
-			if lookupParams.suggestionLevel == LevelAll {
-				continue
+		results, err = s.lookupDictionary(ctx, input, dict, &dictParams)
+		for _, sugg := range results {
+			if _, exists := distances[sugg.Word]; !exists {
+				distances[sugg.Word] = sugg.Distance
+				explanations[sugg.Word] = sugg.Explanation
+				words = append(words, sugg.Word)
 			}
+		}
+
+		if len(results) > 0 && results[0].Explanation != nil {
+			dropped = append(dropped, results[0].Explanation.Dropped...)
+		}
 
+		if err != nil {
 			break
 		}
+	}
 
-		candidateHash := getStringHash(candidate)
-		if suggestions, exists := s.dictionaryDeletes.load(dict, candidateHash); exists {
-			for _, suggestion := range suggestions {
-				suggestionLen := suggestion.len
+	// Combine the entries for each word from every dictionary it appears in
+	merged := make(SuggestionList, 0, len(words))
 
-				// Ignore the suggestion if it equals the input
-				if suggestion.str == input {
-					continue
-				}
+	for _, word := range words {
+		var (
+			sugg       *Suggestion
+			mergedFreq float64
+		)
 
-				// Skip the suggestion if:
-				// * Its length difference to the input is greater than the max
-				//   edit distance
-				// * Its length is less than the current candidate (occurs in
-				//   the case of hash collision)
-				// * Its length is the same as the candidate and is *not* the
-				//   candidate (in the case of a hash collision)
-				if abs(suggestionLen-inputLen) > editDistance ||
-					suggestionLen < candidateLen ||
-					(suggestionLen == candidateLen && suggestion.str != candidate) {
-					continue
-				}
+		for _, dict := range lookupParams.dictionaries {
+			entry, exists := s.library.load(dict, word)
+			if !exists || !lookupParams.accept(entry) {
+				continue
+			}
 
-				// Skip suggestion if its edit distance is too far from input
-				suggPrefixLen := min(suggestionLen, prefixLength)
-				if suggPrefixLen > inputPrefixLen &&
-					(suggPrefixLen-candidateLen) > editDistance {
-					continue
+			freq := lookupParams.weight(dict) * float64(entry.Frequency)
+
+			switch lookupParams.mergeStrategy {
+			case MergeFrequencySum:
+				if sugg == nil {
+					sugg = &Suggestion{Distance: distances[word], Dictionary: dict, Entry: entry}
 				}
 
-				var dist int
+				mergedFreq += freq
+			case MergeBoost:
+				if sugg == nil || freq > mergedFreq {
+					sugg = &Suggestion{Distance: distances[word], Dictionary: dict, Entry: entry}
+					mergedFreq = freq
+				}
+			}
+		}
 
-				// If the candidate is an empty string and maps to a bin with
-				// suggestions (i.e. hash collision), ignore the suggestion if
-				// its edit distance with the input is greater than max edit
-				// distance
-				if candidateLen == 0 {
-					dist = max(inputLen, suggestionLen)
-					if dist > editDistance ||
-						!addKey(consideredSuggestions, suggestion.str) {
-						continue
-					}
-				} else if suggestionLen == 1 {
-					// If the length of the suggestion is 1, determine if the
-					// input contains the suggestion. If it does than the edit
-					// distance is input - 1, otherwise it's the length of the
-					// input
-					if strings.Contains(input, suggestion.str) {
-						dist = inputLen - 1
-					} else {
-						dist = inputLen
-					}
+		if sugg != nil {
+			sugg.Frequency = uint64(math.Round(mergedFreq))
 
-					if dist > editDistance ||
-						!addKey(consideredSuggestions, suggestion.str) {
-						continue
-					}
-				} else {
-					if !addKey(consideredSuggestions, suggestion.str) {
-						continue
-					}
-					if dist = lookupParams.distanceFunction(inputRunes, suggestion.runes, editDistance); dist < 1 {
-						continue
-					}
+			if explanation := explanations[word]; explanation != nil {
+				sugg.Explanation = &Explanation{
+					Candidate:  explanation.Candidate,
+					Operations: explanation.Operations,
 				}
+			}
 
-				// Determine whether or not this suggestion should be added to
-				// the results and if so, how.
-				if dist <= editDistance {
-					if len(results) > 0 {
-						switch lookupParams.suggestionLevel {
-						case LevelClosest:
-							if dist < editDistance {
-								results = SuggestionList{}
-							}
-						case LevelBest:
-							entry, _ := s.library.load(lookupParams.dictOpts.name, suggestion.str)
+			merged = append(merged, *sugg)
+		}
+	}
 
-							curFreq := entry.Frequency
-							closestFreq := results[0].Frequency
+	// Score the merged suggestions against each other
+	if lookupParams.scoring() {
+		inputRunes := []rune(input)
+		evidence := 0.0
 
-							if dist < editDistance || curFreq > closestFreq {
-								editDistance = dist
-								results[0] = s.newDictSuggestion(suggestion.str, dist, lookupParams.dictOpts)
-							}
+		for i := range merged {
+			merged[i].Score = score(lookupParams.errorModel, s.library.total(merged[i].Dictionary),
+				editOperations(inputRunes, []rune(merged[i].Word)), merged[i].Frequency)
+			evidence += math.Pow(10, merged[i].Score)
+		}
 
-							continue
-						}
-					}
+		for i := range merged {
+			merged[i].Probability = math.Pow(10, merged[i].Score) / evidence
+		}
+	}
 
-					if lookupParams.suggestionLevel != LevelAll {
-						editDistance = dist
-					}
+	less := lookupParams.less()
+	if lookupParams.suggestionLevel == LevelClosest {
+		less = lessSuggestion
+	}
 
-					results = append(results,
-						s.newDictSuggestion(suggestion.str, dist, lookupParams.dictOpts))
-				}
-			}
-		}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return less(merged[i], merged[j])
+	})
 
-		// Add additional candidates
-		if lengthDiff < editDistance && candidateLen <= prefixLength {
-			if lookupParams.suggestionLevel != LevelAll && lengthDiff > editDistance {
-				continue
-			}
+	// Reduce the merged suggestions to the requested level
+	kept := len(merged)
 
-			for i := 0; i < candidateLen; i++ {
-				deleteWord := removeChar(candidate, i)
+	switch lookupParams.suggestionLevel {
+	case LevelBest:
+		kept = min(kept, 1)
+	case LevelClosest:
+		for i := range merged {
+			if merged[i].Distance != merged[0].Distance {
+				kept = i
 
-				if addKey(consideredDeletes, deleteWord) {
-					candidates = append(candidates, deleteWord)
-				}
+				break
 			}
 		}
 	}
 
-	// Order the results
-	lookupParams.sortFunc(results)
+	if lookupParams.explain {
+		for _, sugg := range merged[kept:] {
+			dropped = append(dropped, sugg.dropped(outranked(merged[0], sugg, lookupParams.rankByScore)))
+		}
+	}
 
-	return results, nil
-}
+	if lookupParams.maxResults > 0 && kept > lookupParams.maxResults {
+		if lookupParams.explain {
+			for _, sugg := range merged[lookupParams.maxResults:kept] {
+				dropped = append(dropped, sugg.dropped(DropMaxResults))
+			}
+		}
 
-type segmentParams struct {
-	lookupOptions []LookupOption
-}
+		kept = lookupParams.maxResults
+	}
 
-func (s *Spell) defaultSegmentParams() *segmentParams {
-	return &segmentParams{
-		lookupOptions: []LookupOption{
-			SuggestionLevel(LevelBest),
-		},
+	if lookupParams.explain {
+		for i := range merged[:kept] {
+			merged[i].Explanation.Dropped = dropped
+		}
 	}
+
+	return merged[:kept], err
 }
 
-// SegmentOption is a function that controls how a Segment is performed. An
-// error will be returned if the SegmentOption is invalid.
-type SegmentOption func(*segmentParams) error
+// lookupDictionary performs a lookup in a single dictionary. The suggestions
+// returned are unordered.
+func (s *Spell) lookupDictionary(ctx context.Context, input, dict string, lookupParams *lookupParams) (SuggestionList, error) {
+	results := SuggestionList{}
+	maxResults := lookupParams.maxResults
+	inputRunes := []rune(input)
 
-// SegmentLookupOpts allows the Lookup() options for the current segmentation to
-// be configured.
-func SegmentLookupOpts(opt ...LookupOption) SegmentOption {
-	return func(sp *segmentParams) error {
-		sp.lookupOptions = opt
+	// Keep track of suggestions that were dropped if the lookup is being
+	// explained
+	var dropped []DroppedSuggestion
 
-		return nil
+	drop := func(sugg Suggestion, reason dropReason) {
+		if lookupParams.explain {
+			dropped = append(dropped, sugg.dropped(reason))
+		}
 	}
-}
 
-// Segment contains details about an individual segment.
-type Segment struct {
-	Input string
-	Entry *Entry
-	Word  string
-}
+	// addResult adds a suggestion to the results. If the number of results
+	// is limited, only the best are kept
+	addResult := func(sugg Suggestion) {
+		if maxResults > 0 {
+			var evicted *Suggestion
 
-// SegmentResult holds the result of a call to Segment().
-type SegmentResult struct {
-	Distance int
-	Segments []Segment
-}
+			results, evicted = pushBounded(results, sugg, maxResults, lookupParams.less())
+			if evicted != nil {
+				drop(*evicted, DropMaxResults)
+			}
+		} else {
+			results = append(results, sugg)
+		}
+	}
 
-// GetWords returns a string slice of words for the segments.
-func (s SegmentResult) GetWords() []string {
-	words := make([]string, 0, len(s.Segments))
-	for _, s := range s.Segments {
-		words = append(words, s.Word)
+	// Keep track of the total probability of every suggestion considered, so
+	// the probability of each result can be normalised
+	scoring := lookupParams.scoring()
+	evidence := 0.0
+
+	var total dictionaryTotal
+	if scoring {
+		total = s.library.total(dict)
 	}
 
-	return words
-}
+	suggest := func(word string, wordRunes []rune, dist int, candidate string) Suggestion {
+		sugg := s.newDictSuggestion(word, dist, dict)
+		if !scoring {
+			return sugg
+		}
 
-// String returns a string representation of the SegmentList.
-func (s SegmentResult) String() string {
-	return strings.Join(s.GetWords(), " ")
-}
+		ops := editOperations(inputRunes, wordRunes)
 
-// Segment takes an input string which may have word concatenations, and
-// attempts to divide it into the most likely set of words by adding spaces at
-// the most appropriate positions.
-//
-// Accepts zero or more SegmentOption that can be used to configure how
-// segmentation occurs.
-func (s *Spell) Segment(input string, opts ...SegmentOption) (*SegmentResult, error) {
-	segmentParams := s.defaultSegmentParams()
+		sugg.Score = score(lookupParams.errorModel, total, ops, sugg.Frequency)
+		evidence += math.Pow(10, sugg.Score)
 
-	for _, opt := range opts {
-		if err := opt(segmentParams); err != nil {
-			return nil, err
+		if lookupParams.explain {
+			sugg.Explanation = &Explanation{
+				Candidate:  candidate,
+				Operations: ops,
+			}
 		}
-	}
 
-	longestWord := int(atomic.LoadUint32(&s.longestWord))
-	if longestWord == 0 {
-		return nil, errors.New("longest word in dictionary has zero length")
+		return sugg
 	}
 
-	cumulativeFreq := float64(atomic.LoadUint64(&s.cumulativeFreq))
-	if cumulativeFreq == 0 {
-		return nil, errors.New("cumulative frequency is zero")
+	finish := func() SuggestionList {
+		if !scoring {
+			return results
+		}
+
+		for i := range results {
+			results[i].Probability = math.Pow(10, results[i].Score) / evidence
+
+			if results[i].Explanation != nil {
+				results[i].Explanation.Dropped = dropped
+			}
+		}
+
+		return results
 	}
 
-	inputLen := len([]rune(input))
+	// The distance of suggestions which may be returned. This narrows as
+	// closer suggestions are found, unless every suggestion is wanted
+	editDistance := int(lookupParams.editDistance)
 
-	arraySize := min(inputLen, longestWord)
-	circularIdx := -1
+	// The distance of suggestions which are examined. When explaining, this
+	// doesn't narrow, so that further suggestions can be recorded as dropped
+	searchDistance := editDistance
 
-	type composition struct {
-		segmentedString string
-		correctedString string
-		distanceSum     int
-		probability     float64
+	narrow := func(dist int) {
+		editDistance = dist
+		if !lookupParams.explain {
+			searchDistance = dist
+		}
 	}
 
-	compositions := make([]composition, arraySize)
+	// consider adds a suggestion at the given distance to the results
+	// according to the suggestion level, or records why it was dropped
+	consider := func(word string, wordRunes []rune, dist int, candidate string) {
+		// A suggestion beyond the distance of those already found is only
+		// examined to explain why it was dropped
+		if dist > editDistance {
+			further := s.newDictSuggestion(word, dist, dict)
+			further.Score = score(lookupParams.errorModel, total,
+				editOperations(inputRunes, wordRunes), further.Frequency)
+			drop(further, DropFurther)
+
+			return
+		}
 
-	for i := 0; i < inputLen; i++ {
-		jMax := min(inputLen-i, longestWord)
+		// Add the suggestion to the results according to the suggestion level
+		sugg := suggest(word, wordRunes, dist, candidate)
+
+		if len(results) > 0 {
+			switch lookupParams.suggestionLevel {
+			case LevelClosest:
+				if dist < editDistance {
+					for _, further := range results {
+						drop(further, DropFurther)
+					}
 
-		for j := 1; j <= jMax; j++ {
-			part := substring(input, i, i+j)
+					results = SuggestionList{}
+				}
+			case LevelBest:
+				// When ranking by score, a further suggestion may still be the
+				// best
+				if lookupParams.rankByScore {
+					if sugg.Score > results[0].Score {
+						drop(results[0], DropLowerScore)
+						results[0] = sugg
+					} else {
+						drop(sugg, DropLowerScore)
+					}
 
-			var topEd, separatorLength int
+					return
+				}
 
-			var topResult string
+				curFreq := sugg.Frequency
+				closestFreq := results[0].Frequency
 
-			var topProbabilityLog float64
+				if dist < editDistance || curFreq > closestFreq {
+					drop(results[0], outranked(sugg, results[0], false))
+					narrow(dist)
+					results[0] = sugg
+				} else {
+					drop(sugg, DropLessFrequent)
+				}
 
-			if unicode.Is(unicode.White_Space, rune(part[0])) {
-				part = substring(input, i+1, i+j)
-			} else {
-				separatorLength = 1
+				return
 			}
+		}
 
-			topEd += len([]rune(part))
-			part = strings.ReplaceAll(part, " ", "")
-			topEd -= len([]rune(part))
+		if lookupParams.suggestionLevel == LevelClosest ||
+			(lookupParams.suggestionLevel == LevelBest && !lookupParams.rankByScore) {
+			narrow(dist)
+		}
 
-			suggestions, err := s.Lookup(part, segmentParams.lookupOptions...)
-			if err != nil {
-				return nil, err
-			}
+		addResult(sugg)
+	}
 
-			if len(suggestions) > 0 {
-				topResult = suggestions[0].Entry.Word
-				topEd += suggestions[0].Distance
-
-				freq := suggestions[0].Frequency
-				topProbabilityLog = math.Log10(float64(freq) / cumulativeFreq)
-			} else {
-				// Unknown word
-				topResult = part
-				topEd += len([]rune(part))
-				topProbabilityLog = math.Log10(10.0 / (cumulativeFreq *
-					math.Pow(10.0, float64(len([]rune(part))))))
-			}
+	inputLen := len(inputRunes)
+	prefixMatch := lookupParams.prefixMatch
+
+	// distance returns the distance between the input and a word, along with
+	// the number of runes of the word that were compared. When matching
+	// prefixes, the input is compared with the closest prefix of the word
+	distance := func(wordRunes []rune, maxDist int) (int, int) {
+		if prefixMatch {
+			return prefixDistance(lookupParams.distanceFunction, inputRunes, wordRunes, maxDist)
+		}
 
-			destinationIdx := (j + circularIdx) % arraySize
+		return lookupParams.distanceFunction(inputRunes, wordRunes, maxDist), len(wordRunes)
+	}
 
-			if i == 0 {
-				compositions[destinationIdx] = composition{
-					segmentedString: part,
-					correctedString: topResult,
-					distanceSum:     topEd,
-					probability:     topProbabilityLog,
+	if prefixMatch {
+		// The delete index only holds the first PrefixLength runes of each
+		// word, so it can't find longer words which start with a shorter
+		// input. Those are found by walking the dictionary's trie instead
+		if inputLen < int(lookupParams.prefixLength) {
+			for _, c := range s.library.complete(dict, inputRunes, searchDistance,
+				lookupParams.completions(), lookupParams.accept) {
+				if done(ctx) {
+					return finish(), interrupted(ctx)
 				}
-			} else if j == longestWord ||
-				((compositions[circularIdx].distanceSum+topEd ==
-					compositions[destinationIdx].distanceSum ||
-					compositions[circularIdx].distanceSum+separatorLength+topEd ==
-						compositions[destinationIdx].distanceSum) &&
-					compositions[destinationIdx].probability < compositions[circularIdx].probability+topProbabilityLog) ||
-				compositions[circularIdx].distanceSum+separatorLength+topEd <
-					compositions[destinationIdx].distanceSum {
-				compositions[destinationIdx] = composition{
-					segmentedString: compositions[circularIdx].segmentedString + " " + part,
-					correctedString: compositions[circularIdx].correctedString + " " + topResult,
-					distanceSum:     compositions[circularIdx].distanceSum + separatorLength + topEd,
-					probability:     compositions[circularIdx].probability + topProbabilityLog,
+
+				wordRunes := []rune(c.Word)
+
+				dist, k := distance(wordRunes, searchDistance)
+				if dist < 0 {
+					continue
 				}
+
+				consider(c.Word, wordRunes[:k], dist, input)
+			}
+
+			return finish(), nil
+		}
+	} else {
+		// Check for an exact match
+		if entry, exists := s.library.load(dict, input); exists && lookupParams.accept(entry) {
+			addResult(suggest(input, inputRunes, 0, input))
+
+			if lookupParams.suggestionLevel != LevelAll {
+				return finish(), nil
 			}
 		}
 
-		circularIdx++
-		if circularIdx == arraySize {
-			circularIdx = 0
+		// If edit distance is 0, just check if input is in the dictionary
+		if editDistance == 0 {
+			return finish(), nil
 		}
 	}
 
-	segmentedString := compositions[circularIdx].segmentedString
-	correctedString := compositions[circularIdx].correctedString
-	segmentedWords := strings.Split(segmentedString, " ")
-	correctedWords := strings.Split(correctedString, " ")
-	segments := make([]Segment, len(correctedWords))
+	prefixLength := int(lookupParams.prefixLength)
 
-	for i, word := range correctedWords {
-		e, err := s.GetEntry(word)
-		if err != nil {
-			return nil, err
+	// Keep track of the deletes we've already considered
+	consideredDeletes := make(map[string]struct{})
+
+	// Keep track of the suggestions we've already considered. When matching
+	// prefixes, the input may itself be a word starting with the input
+	consideredSuggestions := make(map[string]struct{})
+	if !prefixMatch {
+		consideredSuggestions[input] = struct{}{}
+	}
+
+	// Keep a list of words we want to try
+	var candidates []string
+
+	// Restrict the length of the input we'll examine
+	inputPrefixLen := min(inputLen, prefixLength)
+	candidates = append(candidates, substring(input, 0, inputPrefixLen))
+
+	for i := 0; i < len(candidates); i++ {
+		if done(ctx) {
+			return finish(), interrupted(ctx)
 		}
 
-		segments[i] = Segment{
-			Input: segmentedWords[i],
-			Word:  word,
-			Entry: e,
+		candidate := candidates[i]
+		candidateLen := len([]rune(candidate))
+		lengthDiff := inputPrefixLen - candidateLen
+
+		// If the difference between the prefixed input and candidate is larger
+		// than the max edit distance then skip the candidate
+		if lengthDiff > searchDistance {
+			if lookupParams.suggestionLevel == LevelAll {
+				continue
+			}
+
+			break
 		}
-	}
 
-	result := SegmentResult{
-		Distance: compositions[circularIdx].distanceSum,
-		Segments: segments,
+		candidateHash := getStringHash(candidate)
+		if suggestions, exists := s.dictionaryDeletes.load(dict, candidateHash); exists {
+			for _, suggestion := range suggestions {
+				suggestionLen := suggestion.len
+
+				// Ignore the suggestion if it equals the input
+				if suggestion.str == input && !prefixMatch {
+					continue
+				}
+
+				// Skip the suggestion if:
+				// * Its length difference to the input is greater than the max
+				//   edit distance, or when matching prefixes, it's shorter
+				//   than the input by more than the max edit distance
+				// * Its length is less than the current candidate (occurs in
+				//   the case of hash collision)
+				// * Its length is the same as the candidate and is *not* the
+				//   candidate (in the case of a hash collision)
+				if (!prefixMatch && abs(suggestionLen-inputLen) > searchDistance) ||
+					(prefixMatch && inputLen-suggestionLen > searchDistance) ||
+					suggestionLen < candidateLen ||
+					(suggestionLen == candidateLen && suggestion.str != candidate) {
+					continue
+				}
+
+				// Skip suggestion if its edit distance is too far from input
+				suggPrefixLen := min(suggestionLen, prefixLength)
+				if suggPrefixLen > inputPrefixLen &&
+					(suggPrefixLen-candidateLen) > searchDistance {
+					continue
+				}
+
+				// Skip the suggestion if it's less frequent than required or
+				// excluded by a filter
+				if lookupParams.minFrequency > 0 || len(lookupParams.filters) > 0 {
+					if entry, _ := s.library.load(dict, suggestion.str); !lookupParams.accept(entry) {
+						if lookupParams.explain && addKey(consideredSuggestions, suggestion.str) {
+							if dist, _ := distance(suggestion.runes, searchDistance); dist > 0 {
+								drop(Suggestion{Distance: dist, Dictionary: dict, Entry: entry}, DropFiltered)
+							}
+						}
+
+						continue
+					}
+				}
+
+				var dist int
+
+				// The runes of the suggestion compared with the input
+				suggRunes := suggestion.runes
+
+				// If the candidate is an empty string and maps to a bin with
+				// suggestions (i.e. hash collision), ignore the suggestion if
+				// its edit distance with the input is greater than max edit
+				// distance
+				if candidateLen == 0 && !prefixMatch {
+					dist = max(inputLen, suggestionLen)
+					if dist > searchDistance ||
+						!addKey(consideredSuggestions, suggestion.str) {
+						continue
+					}
+				} else if suggestionLen == 1 && !prefixMatch {
+					// If the length of the suggestion is 1, determine if the
+					// input contains the suggestion. If it does than the edit
+					// distance is input - 1, otherwise it's the length of the
+					// input
+					if strings.Contains(input, suggestion.str) {
+						dist = inputLen - 1
+					} else {
+						dist = inputLen
+					}
+
+					if dist > searchDistance ||
+						!addKey(consideredSuggestions, suggestion.str) {
+						continue
+					}
+				} else {
+					if !addKey(consideredSuggestions, suggestion.str) {
+						continue
+					}
+
+					// Once the results are full, a suggestion can only be
+					// added if it's no further away than the worst result.
+					// When ranking by score, a further suggestion may still
+					// score higher
+					maxDist := searchDistance
+					if maxResults > 0 && len(results) == maxResults && !lookupParams.rankByScore {
+						maxDist = min(maxDist, results[0].Distance)
+					}
+
+					var k int
+					if dist, k = distance(suggestion.runes, maxDist); dist < 0 || (dist == 0 && !prefixMatch) {
+						continue
+					}
+
+					suggRunes = suggestion.runes[:k]
+				}
+
+				consider(suggestion.str, suggRunes, dist, candidate)
+			}
+		}
+
+		// Add additional candidates
+		if lengthDiff < searchDistance && candidateLen <= prefixLength {
+			if lookupParams.suggestionLevel != LevelAll && lengthDiff > searchDistance {
+				continue
+			}
+
+			for i := 0; i < candidateLen; i++ {
+				deleteWord := removeChar(candidate, i)
+
+				if addKey(consideredDeletes, deleteWord) {
+					candidates = append(candidates, deleteWord)
+				}
+			}
+		}
 	}
 
-	return &result, nil
+	return finish(), nil
 }
 
 func (s *Spell) generateDeletes(word string, editDistance uint32, deletes deletes) deletes {
@@ -850,9 +1445,36 @@ func (s *Spell) getDeletes(word string) deletes {
 	return s.generateDeletes(word, 0, deletes)
 }
 
+// shardCount is the number of shards each dictionary's words and deletes are
+// divided between, so that concurrent lookups rarely contend for the same
+// lock. It must be a power of two.
+const shardCount = 64
+
+// shardIndex returns the shard holding a given hash.
+func shardIndex(hash uint32) int {
+	return int(hash & (shardCount - 1))
+}
+
+// shardPadding pads each shard to its own cache line, so that locking one
+// shard doesn't slow access to its neighbours.
+type shardPadding [64]byte
+
+// dictionaryDeletes holds the deletes of each dictionary. The map of
+// dictionaries is copied whenever a dictionary is created, so that a
+// dictionary can be found without locking. Its shards are still read under
+// their own read locks.
 type dictionaryDeletes struct {
+	mu           sync.Mutex
+	dictionaries atomic.Pointer[map[string]*shardedDeletes]
+}
+
+type shardedDeletes [shardCount]deletesShard
+
+// deletesShard holds the deletes whose hashes fall within a shard.
+type deletesShard struct {
 	sync.RWMutex
-	dictionaries map[string]deletesMap
+	deletes deletesMap
+	_       shardPadding
 }
 
 type deletesMap map[uint32][]*deleteEntry
@@ -864,78 +1486,332 @@ type deleteEntry struct {
 }
 
 func newDictionaryDeletes() *dictionaryDeletes {
-	return &dictionaryDeletes{
-		dictionaries: make(map[string]deletesMap),
+	dd := new(dictionaryDeletes)
+	dd.dictionaries.Store(&map[string]*shardedDeletes{})
+
+	return dd
+}
+
+// dictionary returns the deletes of a given dictionary, creating them if they
+// don't exist and create is true.
+func (dd *dictionaryDeletes) dictionary(dict string, create bool) *shardedDeletes {
+	if deletes, exists := (*dd.dictionaries.Load())[dict]; exists || !create {
+		return deletes
 	}
+
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+
+	current := *dd.dictionaries.Load()
+	if deletes, exists := current[dict]; exists {
+		return deletes
+	}
+
+	deletes := new(shardedDeletes)
+	for i := range deletes {
+		deletes[i].deletes = make(deletesMap)
+	}
+
+	dictionaries := make(map[string]*shardedDeletes, len(current)+1)
+	for name, d := range current {
+		dictionaries[name] = d
+	}
+
+	dictionaries[dict] = deletes
+	dd.dictionaries.Store(&dictionaries)
+
+	return deletes
 }
 
 func (dd *dictionaryDeletes) load(dict string, key uint32) ([]*deleteEntry, bool) {
-	dd.RLock()
-	entry, exists := dd.dictionaries[dict][key]
-	dd.RUnlock()
+	deletes := dd.dictionary(dict, false)
+	if deletes == nil {
+		return nil, false
+	}
+
+	shard := &deletes[shardIndex(key)]
+
+	shard.RLock()
+	entry, exists := shard.deletes[key]
+	shard.RUnlock()
 
 	return entry, exists
 }
 
 func (dd *dictionaryDeletes) add(dict string, key uint32, entry *deleteEntry) {
-	dd.Lock()
-	if _, exists := dd.dictionaries[dict]; !exists {
-		dd.dictionaries[dict] = make(deletesMap)
+	shard := &dd.dictionary(dict, true)[shardIndex(key)]
+
+	shard.Lock()
+	shard.deletes[key] = append(shard.deletes[key], entry)
+	shard.Unlock()
+}
+
+// counts returns the number of distinct hashes of deletes in each dictionary.
+func (dd *dictionaryDeletes) counts() map[string]int {
+	counts := make(map[string]int)
+
+	for dict, deletes := range *dd.dictionaries.Load() {
+		for i := range deletes {
+			shard := &deletes[i]
+
+			shard.RLock()
+			counts[dict] += len(shard.deletes)
+			shard.RUnlock()
+		}
 	}
 
-	dd.dictionaries[dict][key] = append(dd.dictionaries[dict][key], entry)
-	dd.Unlock()
+	return counts
 }
 
-// library is a collection of dictionaries.
+// library is a collection of dictionaries. The map of dictionaries is copied
+// whenever a dictionary is created, so that a dictionary can be found without
+// locking. Its shards are still read under their own read locks.
 type library struct {
+	mu           sync.Mutex
+	dictionaries atomic.Pointer[map[string]*libraryDictionary]
+}
+
+// libraryDictionary holds the words of a dictionary, divided between shards by
+// the hash of each word, along with a trie of the words and their totals.
+type libraryDictionary struct {
+	shards [shardCount]dictionaryShard
+
+	// Guards trie, and serializes changes to the dictionary so that total is
+	// kept consistent with its words
+	mu    sync.RWMutex
+	trie  *trieNode
+	total atomic.Pointer[dictionaryTotal]
+}
+
+// dictionaryShard holds the words whose hashes fall within a shard.
+type dictionaryShard struct {
 	sync.RWMutex
-	dictionaries map[string]dictionary
+	words dictionary
+	_     shardPadding
 }
 
 // dictionary is a mapping of a word to its dictionary entry.
 type dictionary map[string]Entry
 
+// dictionaryTotal holds the number of words in a dictionary and the sum of
+// their frequencies.
+type dictionaryTotal struct {
+	words     uint64
+	frequency uint64
+}
+
 // newLibrary creates an empty library of dictionaries.
 func newLibrary() *library {
-	return &library{
-		dictionaries: make(map[string]dictionary),
+	l := new(library)
+	l.dictionaries.Store(&map[string]*libraryDictionary{})
+
+	return l
+}
+
+// dictionary returns a given dictionary, creating it if it doesn't exist and
+// create is true.
+func (l *library) dictionary(dict string, create bool) *libraryDictionary {
+	if d, exists := (*l.dictionaries.Load())[dict]; exists || !create {
+		return d
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current := *l.dictionaries.Load()
+	if d, exists := current[dict]; exists {
+		return d
+	}
+
+	d := &libraryDictionary{trie: new(trieNode)}
+	d.total.Store(&dictionaryTotal{})
+
+	for i := range d.shards {
+		d.shards[i].words = make(dictionary)
 	}
+
+	dictionaries := make(map[string]*libraryDictionary, len(current)+1)
+	for name, existing := range current {
+		dictionaries[name] = existing
+	}
+
+	dictionaries[dict] = d
+	l.dictionaries.Store(&dictionaries)
+
+	return d
+}
+
+// shard returns the shard holding a given word.
+func (d *libraryDictionary) shard(word string) *dictionaryShard {
+	return &d.shards[shardIndex(getStringHash(word))]
+}
+
+// load returns the entry for a word within the dictionary.
+func (d *libraryDictionary) load(word string) (Entry, bool) {
+	shard := d.shard(word)
+
+	shard.RLock()
+	definition, exists := shard.words[word]
+	shard.RUnlock()
+
+	return definition, exists
+}
+
+// entries returns every entry in the dictionary, in no particular order.
+func (d *libraryDictionary) entries() []Entry {
+	entries := make([]Entry, 0, d.total.Load().words)
+
+	for i := range d.shards {
+		shard := &d.shards[i]
+
+		shard.RLock()
+		for _, entry := range shard.words {
+			entries = append(entries, entry)
+		}
+		shard.RUnlock()
+	}
+
+	return entries
 }
 
 // load checks if a word exists in a given dictionary.
 func (l *library) load(dict, word string) (Entry, bool) {
-	l.RLock()
-	definition, exists := l.dictionaries[dict][word]
-	l.RUnlock()
+	d := l.dictionary(dict, false)
+	if d == nil {
+		return Entry{}, false
+	}
 
-	return definition, exists
+	return d.load(word)
 }
 
 // store adds a word to a given dictionary.
 func (l *library) store(dict, word string, definition Entry) {
-	l.Lock()
-	if _, exists := l.dictionaries[dict]; !exists {
-		l.dictionaries[dict] = make(dictionary)
+	d := l.dictionary(dict, true)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	shard := d.shard(word)
+
+	shard.Lock()
+	previous, exists := shard.words[word]
+	shard.words[word] = definition
+	shard.Unlock()
+
+	total := *d.total.Load()
+	if exists {
+		total.frequency -= previous.Frequency
+	} else {
+		total.words++
 	}
 
-	l.dictionaries[dict][word] = definition
+	total.frequency += definition.Frequency
+	d.total.Store(&total)
 
-	l.Unlock()
+	d.trie.insert(word, definition.Frequency)
 }
 
 // remove deletes a word from a given dictionary.
 func (l *library) remove(dict, word string) bool {
-	l.Lock()
-	defer l.Unlock()
+	d := l.dictionary(dict, false)
+	if d == nil {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	shard := d.shard(word)
+
+	shard.Lock()
+	definition, exists := shard.words[word]
+	delete(shard.words, word)
+	shard.Unlock()
+
+	if !exists {
+		return false
+	}
+
+	d.trie.remove(word)
+
+	total := *d.total.Load()
+	total.words--
+	total.frequency -= definition.Frequency
+	d.total.Store(&total)
+
+	return true
+}
+
+// total returns the number of words in a given dictionary and the sum of their
+// frequencies.
+func (l *library) total(dict string) dictionaryTotal {
+	d := l.dictionary(dict, false)
+	if d == nil {
+		return dictionaryTotal{}
+	}
+
+	return *d.total.Load()
+}
+
+// entries returns every entry in a given dictionary, in no particular order.
+func (l *library) entries(dict string) []Entry {
+	d := l.dictionary(dict, false)
+	if d == nil {
+		return nil
+	}
+
+	return d.entries()
+}
+
+// totals returns the totals of each dictionary.
+func (l *library) totals() map[string]dictionaryTotal {
+	dictionaries := *l.dictionaries.Load()
+	totals := make(map[string]dictionaryTotal, len(dictionaries))
+
+	for dict, d := range dictionaries {
+		totals[dict] = *d.total.Load()
+	}
+
+	return totals
+}
+
+// snapshot returns a copy of the words of each dictionary.
+func (l *library) snapshot() map[string]dictionary {
+	dictionaries := *l.dictionaries.Load()
+	snapshot := make(map[string]dictionary, len(dictionaries))
+
+	for dict, d := range dictionaries {
+		words := make(dictionary)
 
-	if _, exists := l.dictionaries[dict][word]; exists {
-		delete(l.dictionaries[dict], word)
+		for i := range d.shards {
+			shard := &d.shards[i]
 
+			shard.RLock()
+			for word, entry := range shard.words {
+				words[word] = entry
+			}
+			shard.RUnlock()
+		}
+
+		snapshot[dict] = words
+	}
+
+	return snapshot
+}
+
+// done reports whether ctx has been cancelled or its deadline has passed.
+func done(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
 		return true
+	default:
+		return false
 	}
+}
 
-	return false
+// interrupted returns the error reported when ctx stops an operation early.
+func interrupted(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrInterrupted, ctx.Err())
 }
 
 func abs(a int) int {
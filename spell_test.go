@@ -1,10 +1,16 @@
 package spell_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
+	"sync"
 	"testing"
 
 	"github.com/eskriett/spell"
@@ -24,6 +30,265 @@ func BenchmarkSpell_Lookup(b *testing.B) {
 	}
 }
 
+// newWithGeneratedWords creates a Spell with n generated words, returning it
+// along with misspellings of some of its words.
+func newWithGeneratedWords(n int) (*spell.Spell, []string, error) {
+	s := spell.New()
+
+	const letters = "etaoinshrdlucmfwyp"
+
+	var misspellings []string
+
+	for i := 0; i < n; i++ {
+		var word []byte
+		for j := i; len(word) < 4 || j > 0; j /= len(letters) {
+			word = append(word, letters[(j+len(word))%len(letters)])
+		}
+
+		if _, err := s.AddEntry(spell.Entry{Word: string(word), Frequency: uint64(i%100 + 1)}); err != nil {
+			return nil, nil, err
+		}
+
+		if i%10 == 0 {
+			word[1], word[2] = word[2], word[1]
+			misspellings = append(misspellings, string(word))
+		}
+	}
+
+	return s, misspellings, nil
+}
+
+// BenchmarkSpell_LookupParallel measures the throughput of concurrent lookups,
+// which should scale with the number of CPUs, e.g. with -cpu 1,2,4,8.
+func BenchmarkSpell_LookupParallel(b *testing.B) {
+	s, misspellings, err := newWithGeneratedWords(20000)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			if _, err := s.Lookup(misspellings[i%len(misspellings)]); err != nil {
+				b.Error(err)
+
+				return
+			}
+		}
+	})
+}
+
+// TestSpell_concurrent adds and removes words while they're looked up,
+// completed, counted and written, and is most useful with -race.
+func TestSpell_concurrent(t *testing.T) {
+	base := map[string]uint64{"example": 1000, "examine": 100, "sample": 10}
+
+	s, err := newWithWords(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const writers, words = 4, 100
+
+	baseWords, baseFreq := uint64(len(base)), uint64(1110)
+
+	// Every word added has a frequency of one, so the totals of a consistent
+	// snapshot of the dictionary always differ from the base by the same amount
+	checkStats := func(stats spell.Stats) {
+		d := stats.Dictionaries["default"]
+		if d.Words < baseWords || d.Words > baseWords+writers*words ||
+			d.Frequency-baseFreq != d.Words-baseWords {
+			t.Errorf("inconsistent stats: %+v", d)
+		}
+	}
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			// Odd words are kept, and even words removed again
+			for i := 0; i < words; i++ {
+				word := fmt.Sprintf("zq%d-%d", w, i)
+
+				if _, err := s.AddEntry(spell.Entry{Word: word, Frequency: 1}); err != nil {
+					t.Error(err)
+
+					return
+				}
+
+				if i%2 == 0 {
+					if removed, err := s.RemoveEntry(word); err != nil || !removed {
+						t.Errorf("failed to remove %s: %v", word, err)
+
+						return
+					}
+				}
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+
+	var readers sync.WaitGroup
+
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+
+		go func(r int) {
+			defer readers.Done()
+
+			// Each reader runs at least once, however quickly the writers finish
+			for {
+				switch r {
+				case 0:
+					suggestions, err := s.Lookup("exampel")
+					if err != nil || len(suggestions) != 1 || suggestions[0].Word != "example" {
+						t.Errorf("expected example, got %v: %v", suggestions, err)
+
+						return
+					}
+				case 1:
+					suggestions, err := s.Autocomplete("exam")
+					if err != nil || len(suggestions) != 2 || suggestions[0].Word != "example" {
+						t.Errorf("expected [example, examine], got %v: %v", suggestions, err)
+
+						return
+					}
+				case 2:
+					checkStats(s.Stats())
+				case 3:
+					var b bytes.Buffer
+					if err := s.Write(&b); err != nil {
+						t.Error(err)
+
+						return
+					}
+
+					read, err := spell.Read(&b)
+					if err != nil {
+						t.Error(err)
+
+						return
+					}
+
+					checkStats(read.Stats())
+				}
+
+				select {
+				case <-done:
+					return
+				default:
+				}
+			}
+		}(r)
+	}
+
+	wg.Wait()
+	close(done)
+	readers.Wait()
+
+	stats := s.Stats()
+	if d := stats.Dictionaries["default"]; d.Words != baseWords+writers*words/2 ||
+		d.Frequency != baseFreq+writers*words/2 {
+		t.Fatalf("expected %d words, got %+v", baseWords+writers*words/2, d)
+	}
+
+	var b bytes.Buffer
+	if err := s.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := spell.Read(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing a word leaves its deletes in the index, so only the words are
+	// compared
+	if got, expected := read.Stats().Dictionaries["default"], stats.Dictionaries["default"]; got.Words !=
+		expected.Words || got.Frequency != expected.Frequency {
+		t.Fatalf("expected %+v once read, got %+v", expected, got)
+	}
+
+	for w := 0; w < writers; w++ {
+		for i := 0; i < words; i++ {
+			entry, err := s.GetEntry(fmt.Sprintf("zq%d-%d", w, i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (entry != nil) != (i%2 == 1) {
+				t.Fatalf("unexpected entry %v for zq%d-%d", entry, w, i)
+			}
+		}
+	}
+}
+
+// BenchmarkSpell_LookupParallelWithWrites measures the throughput of
+// concurrent lookups while one in every hundred operations adds a word.
+func BenchmarkSpell_LookupParallelWithWrites(b *testing.B) {
+	s, misspellings, err := newWithGeneratedWords(20000)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			word := misspellings[i%len(misspellings)]
+
+			if i%100 == 0 {
+				if _, err := s.AddEntry(spell.Entry{Word: word, Frequency: 1}); err != nil {
+					b.Error(err)
+
+					return
+				}
+
+				continue
+			}
+
+			if _, err := s.Lookup(word, spell.SuggestionLevel(spell.LevelClosest)); err != nil {
+				b.Error(err)
+
+				return
+			}
+		}
+	})
+}
+
+// BenchmarkSpell_LookupDefault measures a lookup which doesn't need scores, and
+// so shouldn't pay for them.
+func BenchmarkSpell_LookupDefault(b *testing.B) {
+	benchmarkLookup(b)
+}
+
+// BenchmarkSpell_LookupProbabilities measures the same lookup with each
+// suggestion scored.
+func BenchmarkSpell_LookupProbabilities(b *testing.B) {
+	benchmarkLookup(b, spell.Probabilities())
+}
+
+func benchmarkLookup(b *testing.B, opts ...spell.LookupOption) {
+	s, misspellings, err := newWithGeneratedWords(20000)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		if _, err := s.Lookup(misspellings[n%len(misspellings)], opts...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func ExampleSpell_AddEntry() {
 	// Create a new speller
 	s := spell.New()
@@ -310,3 +575,486 @@ func TestCornerCases(t *testing.T) {
 		t.Fatal(fmt.Sprintf("Expected ' ', got %s", suggestions[0].Word))
 	}
 }
+
+func TestLookupContext(t *testing.T) {
+	s, err := newWithExample()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s.LookupContext(context.Background(), "eample")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 {
+		t.Fatal("did not get exactly one match")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = s.LookupContext(ctx, "eample")
+	if !errors.Is(err, spell.ErrInterrupted) {
+		t.Fatalf("expected ErrInterrupted, got %v", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSegmentContext(t *testing.T) {
+	s := spell.New()
+	for _, word := range []string{"the", "quick", "brown", "fox"} {
+		if _, err := s.AddEntry(spell.Entry{Frequency: 1, Word: word}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result, err := s.SegmentContext(ctx, "thequickbrownfox")
+	if !errors.Is(err, spell.ErrInterrupted) {
+		t.Fatalf("expected ErrInterrupted, got %v", err)
+	}
+	if result == nil || result.String() != "thequickbrownfox" {
+		t.Fatalf("expected unsegmented remainder, got %v", result)
+	}
+
+	result, err = s.SegmentContext(context.Background(), "thequickbrownfox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.String() != "the quick brown fox" {
+		t.Fatalf("expected 'the quick brown fox', got %v", result)
+	}
+}
+
+func newWithWords(words map[string]uint64) (*spell.Spell, error) {
+	s := spell.New()
+	for word, freq := range words {
+		if _, err := s.AddEntry(spell.Entry{Frequency: freq, Word: word}); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func TestLookup_maxResults(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"cat": 10, "bat": 50, "hat": 30, "rat": 20, "cart": 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s.Lookup("xat",
+		spell.SuggestionLevel(spell.LevelAll), spell.MaxResults(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[bat, hat]" {
+		t.Fatalf("expected [bat, hat], got %s", got)
+	}
+
+	if _, err := s.Lookup("xat", spell.MaxResults(0)); err == nil {
+		t.Fatal("expected error for zero max results")
+	}
+}
+
+func TestLookup_minFrequency(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"cat": 10, "bat": 50, "hat": 30, "rat": 20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suggestions, err := s.Lookup("cat",
+		spell.SuggestionLevel(spell.LevelAll), spell.MinFrequency(25))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[bat, hat]" {
+		t.Fatalf("expected [bat, hat], got %s", got)
+	}
+}
+
+func TestLookup_filter(t *testing.T) {
+	s := spell.New()
+	entries := []spell.Entry{
+		{Frequency: 100, Word: "two", WordData: spell.WordData{"type": "number"}},
+		{Frequency: 1, Word: "town", WordData: spell.WordData{"type": "noun"}},
+	}
+	for _, e := range entries {
+		if _, err := s.AddEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	suggestions, err := s.Lookup("twon", spell.WordDataEquals("type", "noun"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[town]" {
+		t.Fatalf("expected [town], got %s", got)
+	}
+
+	suggestions, err = s.Lookup("two", spell.Filter(func(e spell.Entry) bool {
+		return e.Frequency < 10
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[town]" {
+		t.Fatalf("expected [town], got %s", got)
+	}
+
+	suggestions, err = s.Lookup("twon", spell.WordDataHas("plural"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 0 {
+		t.Fatalf("expected no suggestions, got %s", suggestions)
+	}
+}
+
+func TestSaveLoad_dictionaryNames(t *testing.T) {
+	s1 := spell.New()
+	if _, err := s1.AddEntry(spell.Entry{Word: "example", Frequency: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s1.AddEntry(spell.Entry{Word: "exemple", Frequency: 1},
+		spell.DictionaryName("french")); err != nil {
+		t.Fatal(err)
+	}
+
+	filename := filepath.Join(t.TempDir(), "dict.spell")
+	if err := s1.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	s2, err := spell.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries are loaded into the dictionary they were saved from
+	entry, err := s2.GetEntry("exemple", spell.DictionaryName("french"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry == nil {
+		t.Error("expected exemple in the french dictionary")
+	}
+
+	entry, err = s2.GetEntry("exemple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry != nil {
+		t.Error("expected exemple not to be in the default dictionary")
+	}
+}
+
+func TestLookup_wordDataEqualsSaveLoad(t *testing.T) {
+	s := spell.New()
+	entries := []spell.Entry{
+		{Frequency: 100, Word: "two", WordData: spell.WordData{"syllables": 1}},
+		{Frequency: 1, Word: "town", WordData: spell.WordData{"syllables": 2}},
+	}
+	for _, e := range entries {
+		if _, err := s.AddEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filename := filepath.Join(t.TempDir(), "dict.spell")
+	if err := s.Save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := spell.Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []interface{}{2, int64(2), uint8(2), 2.0, float32(2)} {
+		suggestions, err := loaded.Lookup("twon", spell.WordDataEquals("syllables", value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := suggestions.String(); got != "[town]" {
+			t.Errorf("expected [town] for %T, got %s", value, got)
+		}
+	}
+
+	suggestions, err := loaded.Lookup("twon", spell.WordDataEquals("syllables", "2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 0 {
+		t.Errorf("expected a string not to match a number, got %s", suggestions)
+	}
+}
+
+func TestLookup_mergeMaxResults(t *testing.T) {
+	s := spell.New()
+	add := func(dict, word string, freq uint64) {
+		if _, err := s.AddEntry(spell.Entry{Frequency: freq, Word: word},
+			spell.DictionaryName(dict)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("base", "bat", 10)
+	add("base", "hat", 15)
+	add("domain", "bat", 10)
+	add("domain", "rat", 12)
+
+	// Neither dictionary ranks "bat" first, but its summed frequency does
+	suggestions, err := s.Lookup("cat", spell.Dictionaries("base", "domain"),
+		spell.MergeStrategy(spell.MergeFrequencySum), spell.SuggestionLevel(spell.LevelAll),
+		spell.MaxResults(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Word != "bat" || suggestions[0].Frequency != 20 {
+		t.Fatalf("expected bat with frequency 20, got %v", suggestions)
+	}
+}
+
+func TestMergeStrategy_unknown(t *testing.T) {
+	s := spell.New()
+
+	if _, err := s.Lookup("cat", spell.Dictionaries("base"), spell.MergeStrategy(spell.MergeBoost+1)); err == nil {
+		t.Fatal("expected an error for an unknown merge strategy")
+	}
+}
+
+func TestLookup_dictionaries(t *testing.T) {
+	s := spell.New()
+	add := func(dict, word string, freq uint64) {
+		if _, err := s.AddEntry(spell.Entry{Frequency: freq, Word: word},
+			spell.DictionaryName(dict)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("user", "kubectl", 1)
+	add("base", "cart", 50)
+	add("base", "cast", 20)
+	add("domain", "cast", 40)
+
+	suggestions, err := s.Lookup("kubectal", spell.Dictionaries("user", "domain", "base"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Word != "kubectl" ||
+		suggestions[0].Dictionary != "user" {
+		t.Fatalf("expected kubectl from user, got %v", suggestions)
+	}
+
+	// First hit stops at the domain dictionary
+	suggestions, err = s.Lookup("cazt", spell.Dictionaries("user", "domain", "base"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Word != "cast" ||
+		suggestions[0].Dictionary != "domain" {
+		t.Fatalf("expected cast from domain, got %v", suggestions)
+	}
+
+	// Summed frequencies make cast more likely than cart
+	suggestions, err = s.Lookup("carst", spell.Dictionaries("domain", "base"),
+		spell.MergeStrategy(spell.MergeFrequencySum))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 1 || suggestions[0].Word != "cast" ||
+		suggestions[0].Frequency != 60 {
+		t.Fatalf("expected cast with frequency 60, got %v", suggestions)
+	}
+
+	// Boosting the base dictionary makes cart more likely than cast
+	suggestions, err = s.Lookup("carst", spell.Dictionaries("domain", "base"),
+		spell.MergeStrategy(spell.MergeBoost), spell.DictionaryWeight("base", 2),
+		spell.SuggestionLevel(spell.LevelAll))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[cart, cast]" {
+		t.Fatalf("expected [cart, cast], got %s", got)
+	}
+	if suggestions[0].Dictionary != "base" || suggestions[0].Frequency != 100 {
+		t.Fatalf("expected boosted cart from base, got %+v", suggestions[0])
+	}
+}
+
+func TestLookup_prefixMatch(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{
+		"spelling": 50, "spell": 10, "special": 100, "international": 20,
+		"interrogation": 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Short inputs are completed
+	suggestions, err := s.Lookup("spel", spell.PrefixMatch(), spell.SuggestionLevel(spell.LevelClosest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[spelling, spell]" {
+		t.Fatalf("expected [spelling, spell], got %s", got)
+	}
+
+	suggestions, err = s.Lookup("spwl", spell.PrefixMatch(), spell.EditDistance(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[spelling]" || suggestions[0].Distance != 1 {
+		t.Fatalf("expected [spelling] at distance 1, got %s", got)
+	}
+
+	// Inputs longer than the prefix length are found via the delete index
+	suggestions, err = s.Lookup("internatoin", spell.PrefixMatch(),
+		spell.SuggestionLevel(spell.LevelAll))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[international]" || suggestions[0].Distance != 1 {
+		t.Fatalf("expected [international] at distance 1, got %s", got)
+	}
+
+	suggestions, err = s.Lookup("interrog", spell.PrefixMatch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[interrogation]" || suggestions[0].Distance != 0 {
+		t.Fatalf("expected [interrogation] at distance 0, got %s", got)
+	}
+}
+
+// TestLookup_prefixMatchBruteForce compares prefix matches of inputs shorter
+// than the prefix length, which are found by walking the trie, with the
+// distance of every word's closest prefix.
+func TestLookup_prefixMatchBruteForce(t *testing.T) {
+	const letters = "abcd"
+
+	rng := rand.New(rand.NewSource(1))
+
+	randomWord := func(minLen, maxLen int) string {
+		word := make([]byte, minLen+rng.Intn(maxLen-minLen+1))
+		for i := range word {
+			word[i] = letters[rng.Intn(len(letters))]
+		}
+
+		return string(word)
+	}
+
+	for n := 0; n < 50; n++ {
+		s := spell.New()
+		for i := 0; i < 30; i++ {
+			if _, err := s.AddEntry(spell.Entry{Word: randomWord(1, 8), Frequency: 1}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		for i := 0; i < 20; i++ {
+			input := randomWord(2, 5)
+			maxDist := 1 + rng.Intn(2)
+
+			suggestions, err := s.Lookup(input, spell.PrefixMatch(), spell.EditDistance(uint32(maxDist)),
+				spell.SuggestionLevel(spell.LevelAll))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := make(map[string]int)
+			for _, sugg := range suggestions {
+				got[sugg.Word] = sugg.Distance
+			}
+
+			entries, err := s.Entries()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := make(map[string]int)
+			for _, entry := range entries {
+				word := []rune(entry.Word)
+				for k := 0; k <= len(word); k++ {
+					dist := strmet.DamerauLevenshteinRunes([]rune(input), word[:k], maxDist)
+					if best, ok := expected[entry.Word]; dist >= 0 && (!ok || dist < best) {
+						expected[entry.Word] = dist
+					}
+				}
+			}
+
+			if !reflect.DeepEqual(got, expected) {
+				t.Fatalf("%s within %d: expected %v, got %v", input, maxDist, expected, got)
+			}
+		}
+	}
+}
+
+func TestLookup_prefixMatchShort(t *testing.T) {
+	s, err := newWithWords(map[string]uint64{"spelling": 50, "spell": 10, "special": 100, "spew": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Short inputs are completed subject to the maximum results
+	suggestions, err := s.Lookup("spe", spell.PrefixMatch(), spell.SuggestionLevel(spell.LevelAll),
+		spell.MaxResults(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[special, spelling]" {
+		t.Fatalf("expected [special, spelling], got %s", got)
+	}
+
+	// Swapping adjacent runes is a single edit
+	suggestions, err = s.Lookup("sepc", spell.PrefixMatch(), spell.EditDistance(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[special]" || suggestions[0].Distance != 1 {
+		t.Fatalf("expected [special] at distance 1, got %s", got)
+	}
+
+	// The distance of a completion is given by the distance function
+	suggestions, err = s.Lookup("spwl", spell.PrefixMatch(), spell.EditDistance(1),
+		spell.DistanceFunc(func(r1, r2 []rune, maxDist int) int {
+			if dist := strmet.DamerauLevenshteinRunes(r1, r2, maxDist); dist < 0 {
+				return dist
+			}
+
+			return 0
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[spelling]" || suggestions[0].Distance != 0 {
+		t.Fatalf("expected [spelling] at distance 0, got %s", got)
+	}
+
+	// Completions which aren't returned are explained
+	suggestions, err = s.Lookup("spel", spell.PrefixMatch(), spell.Explain(),
+		spell.SuggestionLevel(spell.LevelBest), spell.MaxResults(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := suggestions.String(); got != "[spelling]" {
+		t.Fatalf("expected [spelling], got %s", got)
+	}
+	if len(suggestions[0].Explanation.Dropped) == 0 {
+		t.Fatal("expected dropped completions to be explained")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.LookupContext(ctx, "spe", spell.PrefixMatch()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2021 Hayden Eskriett. All rights reserved.
+// Use of this source code is governed by a MIT license that can be found in the
+// LICENSE file.
+
+package spell
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// token is a word found within some text.
+type token struct {
+	word               string
+	start, end         int
+	runeStart, runeEnd int
+}
+
+// wordBreakClass is the class of a rune for finding word boundaries, following
+// a subset of the rules in Unicode Standard Annex #29.
+type wordBreakClass int
+
+const (
+	breakOther wordBreakClass = iota
+	breakLetter
+	breakNumeric
+	breakExtendNumLet
+	breakExtend
+	breakMidLetter
+	breakMidNum
+	breakMidNumLet
+	breakSingleQuote
+)
+
+// classify returns the word break class of r.
+func classify(r rune) wordBreakClass {
+	switch {
+	case unicode.Is(unicode.M, r) || unicode.Is(unicode.Cf, r) || r == zeroWidthJoiner:
+		return breakExtend
+	case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r):
+		// Ideographs aren't joined into words
+		return breakOther
+	case unicode.IsLetter(r):
+		return breakLetter
+	case unicode.IsDigit(r):
+		return breakNumeric
+	case unicode.Is(unicode.Pc, r):
+		return breakExtendNumLet
+	}
+
+	switch r {
+	case '\'':
+		return breakSingleQuote
+	case '.', '‘', '’', '․', '﹒', '＇', '．':
+		return breakMidNumLet
+	case ':', '·', '\u0387', '״', '‧', '︓', '﹕', '：':
+		return breakMidLetter
+	case ',', ';', '\u037e', '։', '،', '؍', '٬', '߸',
+		'⁄', '︐', '︔', '﹐', '﹔', '，', '；':
+		return breakMidNum
+	}
+
+	return breakOther
+}
+
+// joins reports whether a rune of class mid joins runes of class before and
+// after into a single word.
+func joins(before, mid, after wordBreakClass) bool {
+	switch mid {
+	case breakMidLetter:
+		return before == breakLetter && after == breakLetter
+	case breakMidNum:
+		return before == breakNumeric && after == breakNumeric
+	case breakMidNumLet, breakSingleQuote:
+		return before == after && (before == breakLetter || before == breakNumeric)
+	}
+
+	return false
+}
+
+// isWordClass reports whether c may start or continue a word.
+func isWordClass(c wordBreakClass) bool {
+	return c == breakLetter || c == breakNumeric || c == breakExtendNumLet
+}
+
+// tokenize splits text into words, following the word boundary rules of
+// Unicode Standard Annex #29 for letters and numbers. Punctuation, symbols and
+// whitespace between words are discarded.
+func tokenize(text string) []token {
+	type position struct {
+		class      wordBreakClass
+		start, end int
+	}
+
+	// Runes of the Extend class belong to the rune before them, so they're
+	// folded into it
+	var positions []position
+
+	for i, r := range text {
+		class := classify(r)
+		end := i + utf8.RuneLen(r)
+
+		if class == breakExtend && len(positions) > 0 {
+			positions[len(positions)-1].end = end
+
+			continue
+		}
+
+		positions = append(positions, position{class: class, start: i, end: end})
+	}
+
+	var tokens []token
+
+	runeOffset := 0
+	runes := func(p position) int {
+		return utf8.RuneCountInString(text[p.start:p.end])
+	}
+
+	for i := 0; i < len(positions); {
+		if !isWordClass(positions[i].class) {
+			runeOffset += runes(positions[i])
+			i++
+
+			continue
+		}
+
+		t := token{start: positions[i].start, runeStart: runeOffset}
+		last := positions[i].class
+		runeOffset += runes(positions[i])
+		i++
+
+		for i < len(positions) {
+			class := positions[i].class
+			if isWordClass(class) {
+				last = class
+				runeOffset += runes(positions[i])
+				i++
+
+				continue
+			}
+
+			if i+1 < len(positions) && joins(last, class, positions[i+1].class) {
+				runeOffset += runes(positions[i]) + runes(positions[i+1])
+				i += 2
+
+				continue
+			}
+
+			break
+		}
+
+		t.end = positions[i-1].end
+		t.runeEnd = runeOffset
+		t.word = text[t.start:t.end]
+		tokens = append(tokens, t)
+	}
+
+	return tokens
+}